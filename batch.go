@@ -0,0 +1,123 @@
+package tricks
+
+import (
+	"reflect"
+	"time"
+)
+
+// A Clock makes the timers that stream operators like Batch wait on. Streams
+// use the real time by default; tests can swap in a Clock of their own with
+// WithClock, and fire its timers by hand rather than sleeping.
+type Clock interface {
+	// NewTimer returns a channel that receives the time once d has passed,
+	// and a function that stops the timer, like time.Timer.Stop.
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+type realClock struct{}
+
+func (realClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// WithClock returns the same stream, using the given clock for any operators
+// that wait, in this and later stages. A nil clock means the real time.
+func (s *TrickStream) WithClock(c Clock) *TrickStream {
+	if c == nil {
+		c = realClock{}
+	}
+	return &TrickStream{s.ctx, s.ch, c, s.err}
+}
+
+// Batch returns a new stream of slices (`[]T`) of the values of this one. A
+// batch is sent once it holds size values, or once maxWait has passed since its
+// first value arrived, whichever comes first. A maxWait of zero means batches
+// only wait to fill up. When the stream ends, whatever is left is sent as a
+// last, smaller batch.
+func (s *TrickStream) Batch(size int, maxWait time.Duration) *TrickStream {
+	if size < 1 {
+		panic("tricks: stream.Batch: size must be at least 1")
+	}
+	sliceType := reflect.SliceOf(s.ch.Type().Elem())
+	out := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, sliceType), 0)
+	var err error
+
+	go func() {
+		defer out.Close()
+		batch := reflect.MakeSlice(sliceType, 0, size)
+		var timeout reflect.Value // <-chan time.Time, while a batch is waiting
+		var stop func() bool
+		flush := func() bool {
+			if stop != nil {
+				stop()
+				timeout, stop = reflect.Value{}, nil
+			}
+			if batch.Len() == 0 {
+				return true
+			}
+			full := batch
+			batch = reflect.MakeSlice(sliceType, 0, size)
+			if !s.send(out, full) {
+				err = s.ctx.Err()
+				return false
+			}
+			return true
+		}
+
+		for {
+			chosen, val, ok := reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: s.ch},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctx.Done())},
+				{Dir: reflect.SelectRecv, Chan: timeout},
+			})
+			switch chosen {
+			case 0: // a value, or the end of the stream
+				if !ok {
+					if flush() {
+						err = s.closedErr()
+					}
+					return
+				}
+				batch = reflect.Append(batch, val)
+				if batch.Len() == 1 && maxWait > 0 {
+					var c <-chan time.Time
+					c, stop = s.clock.NewTimer(maxWait)
+					timeout = reflect.ValueOf(c)
+				}
+				if batch.Len() == size && !flush() {
+					return
+				}
+			case 1: // the context is done
+				err = s.ctx.Err()
+				return
+			case 2: // the batch has waited long enough
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+
+	return &TrickStream{s.ctx, out, s.clock, &err}
+}
+
+// Batch splits the slice into a slice of batches (`[][]T`), each holding size
+// elements, except for the last, which holds whatever is left. The batches
+// reslice the original slice, with their cap() set to equal their length.
+func (ts TrickSlice) Batch(size int) TrickSlice {
+	if size < 1 {
+		panic("tricks: slice.Batch: size must be at least 1")
+	}
+	v := reflect.Value(ts)
+	n := (v.Len() + size - 1) / size
+	out := reflect.MakeSlice(reflect.SliceOf(v.Type()), n, n)
+	for i := 0; i < n; i++ {
+		lo, hi := i*size, (i+1)*size
+		if hi > v.Len() {
+			hi = v.Len()
+		}
+		out.Index(i).Set(v.Slice3(lo, hi, hi))
+	}
+	return TrickSlice(out)
+}
@@ -0,0 +1,82 @@
+package tricks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A fakeClock hands each timer it makes to the test, which fires it by hand.
+type fakeClock struct {
+	timers chan chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{make(chan chan time.Time, 10)}
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := make(chan time.Time, 1)
+	c.timers <- t
+	return t, func() bool { return true }
+}
+
+func TestSliceBatch(t *testing.T) {
+	batches := Slice([]int{1, 2, 3, 4, 5}).Batch(2).Value().([][]int)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+	assert.Equal(t, 2, cap(batches[0]))
+
+	assert.Equal(t, [][]int{{1, 2, 3}}, Slice([]int{1, 2, 3}).Batch(5).Value())
+	assert.Equal(t, [][]string{}, Slice([]string{}).Batch(5).Value())
+	assert.Panics(t, func() { Slice([]int{1}).Batch(0) })
+}
+
+func TestStreamBatchBySize(t *testing.T) {
+	out, err := Stream(context.Background(), countTo(7)).Batch(3, 0).Collect()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, out.Value())
+
+	assert.Panics(t, func() { Stream(context.Background(), countTo(0)).Batch(0, time.Second) })
+}
+
+func TestStreamBatchByTime(t *testing.T) {
+	clock := newFakeClock()
+	in := make(chan string)
+	batches := Stream(context.Background(), in).WithClock(clock).Batch(3, time.Minute).Chan().(<-chan []string)
+
+	// A batch that fills up before its timer fires.
+	in <- "a"
+	<-clock.timers
+	in <- "b"
+	in <- "c"
+	assert.Equal(t, []string{"a", "b", "c"}, <-batches)
+
+	// A batch that is sent when its timer fires.
+	in <- "d"
+	timer := <-clock.timers
+	in <- "e"
+	timer <- time.Time{}
+	assert.Equal(t, []string{"d", "e"}, <-batches)
+
+	// Whatever's left when the stream ends.
+	in <- "f"
+	<-clock.timers
+	close(in)
+	assert.Equal(t, []string{"f"}, <-batches)
+	_, ok := <-batches
+	assert.False(t, ok)
+}
+
+func TestStreamBatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	batches := Stream(ctx, in).Batch(10, 0)
+
+	in <- 1
+	cancel()
+	out, err := batches.Collect()
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, out.Len())
+}
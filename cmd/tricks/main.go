@@ -0,0 +1,244 @@
+// Command tricks runs a query over JSON read from standard input, and writes
+// the result as JSON, CSV or a table. It's a little like jq, but with the
+// operations of the tricks package. For example:
+//
+//	tricks -o table 'filter(.status == "ok")' 'sort_by(-.latency)' 'first(10)' < requests.json
+//
+// Each argument is a stage of the query, or several stages separated by pipes;
+// see tricks.ParseQuery for the stages there are, and the expressions they
+// take. Most are named after the slice and map methods, in snake case, so
+// grouping is group_by (not groupby) and sorting by a field is sort_by. With
+// no arguments, the input is written out as it is, which is handy for turning
+// JSON into a table.
+//
+// The input may be a single JSON value, or a stream of them, such as JSON
+// Lines, in which case they're read into a slice.
+//
+// Usage:
+//
+//	tricks [-lines] [-o format] [stage ...]
+//
+// The flags are:
+//
+//	-lines
+//		read the input into a slice, even if it's a single value
+//	-o format
+//		write the output as json (the default), lines, csv, tsv, table or
+//		markdown
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aviddiviner/tricks"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch err {
+	case nil:
+	case flag.ErrHelp, errUsage:
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("usage")
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("tricks", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	lines := flags.Bool("lines", false, "read the input into a slice, even if it's a single value")
+	format := flags.String("o", "json", "write the output as `format`: json, lines, csv, tsv, table or markdown")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: tricks [-lines] [-o format] [stage ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "tricks: unknown output format %q\n", *format)
+		flags.Usage()
+		return errUsage
+	}
+
+	var q *tricks.Query
+	if flags.NArg() > 0 {
+		var err error
+		if q, err = tricks.ParseQuery(strings.Join(flags.Args(), " | ")); err != nil {
+			return describeQueryError(err)
+		}
+	}
+
+	data, err := readInput(stdin, *lines)
+	if err != nil {
+		return err
+	}
+	if q != nil {
+		if data, err = q.Run(data); err != nil {
+			return describeQueryError(err)
+		}
+	}
+	return write(stdout, data)
+}
+
+// readInput decodes all the JSON values in r. If there's just one, and lines
+// isn't set, it's returned by itself; otherwise they're returned in a slice.
+func readInput(r io.Reader, lines bool) (interface{}, error) {
+	dec := json.NewDecoder(r)
+	values := []interface{}{}
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tricks: reading value %d of input: %v", len(values)+1, err)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 1 && !lines {
+		return values[0], nil
+	}
+	return values, nil
+}
+
+// describeQueryError adds the query to err, pointing out where the problem
+// is, since the query was put together from the arguments.
+func describeQueryError(err error) error {
+	var query string
+	var pos int
+	switch e := err.(type) {
+	case *tricks.QuerySyntaxError:
+		query, pos = e.Query, e.Pos
+	case *tricks.QueryError:
+		query, pos = e.Query, e.Pos
+	default:
+		return err
+	}
+	caret := strings.Repeat(" ", utf8.RuneCountInString(query[:pos])) + "^"
+	return fmt.Errorf("%v\n\t%s\n\t%s", err, query, caret)
+}
+
+var writers = map[string]func(io.Writer, interface{}) error{
+	"json":     writeJSON,
+	"lines":    writeLines,
+	"csv":      writeCSV(','),
+	"tsv":      writeCSV('\t'),
+	"table":    writeTable(false),
+	"markdown": writeTable(true),
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// writeLines writes the elements of a slice as JSON Lines, or anything else
+// as a single line.
+func writeLines(w io.Writer, v interface{}) error {
+	items, ok := asSlice(v)
+	if !ok {
+		items = []interface{}{v}
+	}
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(comma rune) func(io.Writer, interface{}) error {
+	return func(w io.Writer, v interface{}) error {
+		items, ok := asSlice(v)
+		if !ok {
+			return writeScalar(w, v, "csv")
+		}
+		for _, item := range items {
+			if rv := reflect.ValueOf(item); rv.IsValid() && (rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String) {
+				return fmt.Errorf("tricks: csv output needs a slice of objects, not %s", describe(item))
+			}
+		}
+		return tricks.Slice(items).ToCSV(w, &tricks.CSVOptions{Comma: comma})
+	}
+}
+
+func writeTable(markdown bool) func(io.Writer, interface{}) error {
+	return func(w io.Writer, v interface{}) error {
+		opts := &tricks.TableOptions{Markdown: markdown}
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Slice:
+			return tricks.Slice(v).Table(w, opts)
+		case reflect.Map:
+			return tricks.Map(v).Table(w, opts)
+		}
+		return writeScalar(w, v, "table")
+	}
+}
+
+// writeScalar writes a result that isn't a slice or map, such as a count, as
+// plain text: strings as they are, and anything else as JSON. Maps can't be
+// written as CSV.
+func writeScalar(w io.Writer, v interface{}, format string) error {
+	if reflect.ValueOf(v).Kind() == reflect.Map {
+		return fmt.Errorf("tricks: %s output needs a slice of objects, not %s", format, describe(v))
+	}
+	if s, ok := v.(string); ok {
+		_, err := fmt.Fprintln(w, s)
+		return err
+	}
+	return writeLines(w, v)
+}
+
+// asSlice copies the elements of v into a []interface{}, if it's a slice.
+func asSlice(v interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// describe names the kind of a decoded JSON value, for error messages.
+func describe(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a bool"
+	case float64, int:
+		return "a number"
+	case string:
+		return "a string"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice:
+		return "a slice"
+	case reflect.Map:
+		return "a map"
+	}
+	return fmt.Sprintf("a %T", v)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testInput = `{"id": 1, "status": "ok", "region": "eu", "latency": 120}
+{"id": 2, "status": "error", "region": "us", "latency": 950}
+{"id": 3, "status": "ok", "region": "us", "latency": 80}
+{"id": 4, "status": "ok", "region": "eu", "latency": 45}
+`
+
+func runTricks(input string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(input), &stdout, &stderr)
+	return stdout.String() + stderr.String(), err
+}
+
+func TestRunJSON(t *testing.T) {
+	out, err := runTricks(testInput, `filter(.status == "ok")`, `sort_by(.latency) | map(.id)`)
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n  4,\n  3,\n  1\n]\n", out)
+
+	out, err = runTricks(testInput, `count_by(.region)`)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"eu\": 2,\n  \"us\": 2\n}\n", out)
+
+	out, err = runTricks(`[3, 1, 2]`, "-o", "lines", "sort")
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", out)
+}
+
+func TestRunLines(t *testing.T) {
+	// A single value is taken as it is, unless -lines is given.
+	out, err := runTricks(`{"a": 1}`, "count")
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n", out)
+
+	out, err = runTricks(`{"a": 1}`, "-lines", "map(.a)")
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n  1\n]\n", out)
+
+	out, err = runTricks(``, "count")
+	assert.NoError(t, err)
+	assert.Equal(t, "0\n", out)
+}
+
+func TestRunCSV(t *testing.T) {
+	out, err := runTricks(testInput, "-o", "csv", "filter(.latency < 100)")
+	assert.NoError(t, err)
+	assert.Equal(t, "id,latency,region,status\n3,80,us,ok\n4,45,eu,ok\n", out)
+
+	out, err = runTricks(testInput, "-o", "tsv", "first(1)")
+	assert.NoError(t, err)
+	assert.Equal(t, "id\tlatency\tregion\tstatus\n1\t120\teu\tok\n", out)
+
+	_, err = runTricks(testInput, "-o", "csv", "map(.id)")
+	assert.EqualError(t, err, "tricks: csv output needs a slice of objects, not a number")
+
+	_, err = runTricks(testInput, "-o", "csv", "group_by(.region)")
+	assert.EqualError(t, err, "tricks: csv output needs a slice of objects, not a map")
+}
+
+func TestRunTable(t *testing.T) {
+	out, err := runTricks(testInput, "-o", "table", "filter(.region == \"eu\")")
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"id  latency  region  status\n"+
+		"--  -------  ------  ------\n"+
+		" 1      120  eu      ok\n"+
+		" 4       45  eu      ok\n", out)
+
+	out, err = runTricks(testInput, "-o", "markdown", "count_by(.status)")
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"| Key   | Value |\n"+
+		"| ----- | ----: |\n"+
+		"| error |     1 |\n"+
+		"| ok    |     3 |\n", out)
+
+	out, err = runTricks(testInput, "-o", "table", `map(.region) | uniq | join(" ")`)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu us\n", out)
+}
+
+func TestRunErrors(t *testing.T) {
+	_, err := runTricks(testInput, "filter(.latency >)", "count")
+	assert.EqualError(t, err, ""+
+		"tricks: query: syntax error at column 18: expected an expression, found \")\"\n"+
+		"\tfilter(.latency >) | count\n"+
+		"\t                 ^")
+
+	_, err = runTricks(testInput, "count_by(.region)", "first(2)")
+	assert.EqualError(t, err, ""+
+		"tricks: query: first at column 21: needs a slice, got a map\n"+
+		"\tcount_by(.region) | first(2)\n"+
+		"\t                    ^")
+
+	_, err = runTricks(`{"a": 1} {"b":`)
+	assert.EqualError(t, err, "tricks: reading value 2 of input: unexpected EOF")
+
+	out, err := runTricks(testInput, "-o", "xml")
+	assert.Equal(t, errUsage, err)
+	assert.Contains(t, out, `tricks: unknown output format "xml"`)
+}
@@ -0,0 +1,321 @@
+package tricks
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// CSVOptions control how ToCSV writes, and SliceFromCSV reads, CSV data. A nil
+// *CSVOptions is the same as the zero value: comma-separated, with a header
+// row, and all the columns.
+type CSVOptions struct {
+	// Comma is the field delimiter. It defaults to ','; use '\t' for TSV.
+	Comma rune
+
+	// Columns chooses which columns to write, and in what order, by their
+	// header names. When reading, other columns are ignored. If nil, all the
+	// columns are used.
+	Columns []string
+
+	// NoHeader leaves out the header row when writing. When reading, it means
+	// there's no header row to read, and the columns are the ones named by
+	// Columns, or else all the fields of the struct, in order. Maps have no
+	// fields, so reading them without a header needs Columns.
+	NoHeader bool
+
+	// Formatters are used when writing values of the given types, instead of
+	// the default formatting (MarshalText for types that implement
+	// encoding.TextMarshaler, like time.Time, or else fmt.Sprint).
+	Formatters map[reflect.Type]func(interface{}) string
+}
+
+func (o *CSVOptions) comma() rune {
+	if o.Comma == 0 {
+		return ','
+	}
+	return o.Comma
+}
+
+// A CSVError describes a cell that couldn't be read into the field or map
+// value for its column.
+type CSVError struct {
+	Row    int    // the row, counting from 1 (including any header row)
+	Column int    // the column, counting from 1
+	Header string // the name of the column
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("tricks: SliceFromCSV: row %d, column %d (%s): %v", e.Row, e.Column, e.Header, e.Err)
+}
+
+// A column is a struct field, by its index, or a map key, if index is nil.
+type column struct {
+	name  string
+	index []int
+}
+
+// columnsOf lists the columns for the rows of v: the fields of a struct, named
+// by their tagKey tags like ToMap names them by `json` tags, or the keys found
+// in a slice of maps, sorted. If only is given, just those columns are
+// returned.
+func columnsOf(context string, v reflect.Value, tagKey string, only []string) []column {
+	elemType := v.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	var cols []column
+	isStruct := elemType.Kind() == reflect.Struct
+	if isStruct {
+		for _, f := range structFields(elemType, tagKey) {
+			cols = append(cols, column{f.name, f.index})
+		}
+	} else {
+		seen := make(map[string]bool)
+		var names []string
+		for i := 0; i < v.Len(); i++ {
+			row := indirect(v.Index(i))
+			if !row.IsValid() {
+				continue
+			}
+			if row.Kind() != reflect.Map || row.Type().Key().Kind() != reflect.String {
+				panic("tricks: " + context + ": elements are not structs or maps with string keys")
+			}
+			for _, key := range row.MapKeys() {
+				if name := key.String(); !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cols = append(cols, column{name, nil})
+		}
+	}
+	if only == nil {
+		return cols
+	}
+
+	picked := make([]column, len(only))
+	for i, name := range only {
+		picked[i] = column{name, nil}
+		found := false
+		for _, col := range cols {
+			if col.name == name {
+				picked[i], found = col, true
+				break
+			}
+		}
+		if isStruct && !found {
+			panic(fmt.Sprintf("tricks: %s: no column %q in %s", context, name, elemType))
+		}
+	}
+	return picked
+}
+
+// cell returns the value in row for the column, or an invalid value if there
+// isn't one.
+func (col column) cell(row reflect.Value) reflect.Value {
+	switch {
+	case !row.IsValid():
+		return row
+	case col.index != nil:
+		return fieldByIndex(row, col.index)
+	}
+	return row.MapIndex(reflect.ValueOf(col.name).Convert(row.Type().Key()))
+}
+
+// formatCell formats a value for a CSV or table cell, using the formatter for
+// its type if there is one.
+func formatCell(formatters map[reflect.Type]func(interface{}) string, v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	if f, ok := formatters[v.Type()]; ok {
+		return f(v.Interface())
+	}
+	if v = indirect(v); !v.IsValid() {
+		return ""
+	}
+	if f, ok := formatters[v.Type()]; ok {
+		return f(v.Interface())
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ToCSV writes the slice to w as CSV, one row per element. The elements must
+// be structs (or pointers to structs), in which case the columns are their
+// exported fields, named by their `csv` tags if they have them; or maps with
+// string keys (such as decoded JSON), in which case the columns are all the
+// keys found, sorted. Nil values are written as empty cells.
+func (ts TrickSlice) ToCSV(w io.Writer, opts *CSVOptions) error {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	v := reflect.Value(ts)
+	cols := columnsOf("slice.ToCSV", v, "csv", opts.Columns)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.comma()
+	record := make([]string, len(cols))
+	if !opts.NoHeader {
+		for i, col := range cols {
+			record[i] = col.name
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := indirect(v.Index(i))
+		for j, col := range cols {
+			record[j] = formatCell(opts.Formatters, col.cell(row))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// SliceFromCSV reads CSV rows from r into the slice that ptrToSlice points to,
+// and returns it as a TrickSlice. The elements may be structs (or pointers to
+// structs), whose fields are matched to the header row by their `csv` tags,
+// falling back to a case-insensitive match; or maps with string keys, which get
+// an entry for each column. Columns with no matching field are ignored, and so
+// are empty cells.
+//
+// Cells are converted to the field types the same way Decode converts strings.
+// If a cell can't be converted, SliceFromCSV returns a *CSVError saying where
+// it is.
+func SliceFromCSV(r io.Reader, ptrToSlice interface{}, opts *CSVOptions) (TrickSlice, error) {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	ptr := reflect.ValueOf(ptrToSlice)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		panic("tricks: SliceFromCSV: input is not a pointer to a slice")
+	}
+	sliceType := ptr.Elem().Type()
+	rowType := sliceType.Elem()
+	for rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+	isStruct := rowType.Kind() == reflect.Struct
+	if !isStruct && (rowType.Kind() != reflect.Map || rowType.Key().Kind() != reflect.String) {
+		panic("tricks: SliceFromCSV: elements are not structs or maps with string keys")
+	}
+	if opts.NoHeader && opts.Columns == nil && !isStruct {
+		panic("tricks: SliceFromCSV: Columns are needed to read maps without a header")
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = opts.comma()
+	row := 0
+
+	header := opts.Columns
+	if !opts.NoHeader {
+		record, err := cr.Read()
+		if err == io.EOF {
+			ptr.Elem().Set(reflect.MakeSlice(sliceType, 0, 0))
+			return TrickSlice(ptr.Elem()), nil
+		}
+		if err != nil {
+			return TrickSlice{}, err
+		}
+		header = append([]string(nil), record...)
+		row++
+	} else if header == nil && isStruct {
+		for _, f := range structFields(rowType, "csv") {
+			header = append(header, f.name)
+		}
+	}
+
+	// Work out where each column goes, leaving nils for those we skip.
+	var fields []taggedField
+	if isStruct {
+		fields = structFields(rowType, "csv")
+	}
+	targets := make([]*column, len(header))
+	for i, name := range header {
+		if !opts.NoHeader && opts.Columns != nil && !containsString(opts.Columns, name) {
+			continue
+		}
+		if !isStruct {
+			targets[i] = &column{name, nil}
+		} else if f := matchField(fields, name); f != nil {
+			targets[i] = &column{f.name, f.index}
+		}
+	}
+
+	out := reflect.MakeSlice(sliceType, 0, 0)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TrickSlice{}, err
+		}
+		row++
+
+		elem := reflect.New(sliceType.Elem()).Elem()
+		dst, _ := allocIndirect(elem)
+		if !isStruct {
+			dst.Set(reflect.MakeMap(rowType))
+		}
+		for i, cell := range record {
+			if i >= len(targets) || targets[i] == nil || cell == "" {
+				continue
+			}
+			if err := targets[i].decode(dst, cell); err != nil {
+				return TrickSlice{}, &CSVError{row, i + 1, header[i], err}
+			}
+		}
+		out = reflect.Append(out, elem)
+	}
+
+	ptr.Elem().Set(out)
+	return TrickSlice(out), nil
+}
+
+// decode sets the field or map entry for the column in dst from a cell.
+func (col column) decode(dst reflect.Value, cell string) error {
+	if col.index != nil {
+		field, err := allocFieldByIndex(dst, col.index)
+		if err != nil {
+			return err
+		}
+		return convertInto(field, reflect.ValueOf(cell), "")
+	}
+	val := reflect.New(dst.Type().Elem()).Elem()
+	if err := convertInto(val, reflect.ValueOf(cell), ""); err != nil {
+		return err
+	}
+	dst.SetMapIndex(reflect.ValueOf(col.name).Convert(dst.Type().Key()), val)
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
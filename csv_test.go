@@ -0,0 +1,138 @@
+package tricks
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testReportRow struct {
+	Name    string    `csv:"name"`
+	Age     int       `csv:"age"`
+	Score   float64   `csv:"score"`
+	Joined  time.Time `csv:"joined"`
+	Active  *bool     `csv:"active,omitempty"`
+	Ignored string    `csv:"-"`
+	secret  string
+}
+
+func TestSliceToCSV(t *testing.T) {
+	yes := true
+	rows := []testReportRow{
+		{"ann", 31, 9.5, time.Date(2016, 10, 3, 0, 0, 0, 0, time.UTC), &yes, "x", "y"},
+		{"bob, jr", 42, 7, time.Time{}, nil, "", ""},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Slice(rows).ToCSV(&buf, nil))
+	assert.Equal(t, "name,age,score,joined,active\n"+
+		"ann,31,9.5,2016-10-03T00:00:00Z,true\n"+
+		"\"bob, jr\",42,7,0001-01-01T00:00:00Z,\n", buf.String())
+
+	buf.Reset()
+	opts := &CSVOptions{
+		Comma:   '\t',
+		Columns: []string{"score", "name"},
+		Formatters: map[reflect.Type]func(interface{}) string{
+			reflect.TypeOf(0.0): func(x interface{}) string { return strings.Repeat("*", int(x.(float64))) },
+		},
+	}
+	assert.NoError(t, Slice(rows).ToCSV(&buf, opts))
+	assert.Equal(t, "score\tname\n*********\tann\n*******\tbob, jr\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Slice(rows[:1]).ToCSV(&buf, &CSVOptions{NoHeader: true, Columns: []string{"age"}}))
+	assert.Equal(t, "31\n", buf.String())
+
+	assert.Panics(t, func() { Slice(rows).ToCSV(&buf, &CSVOptions{Columns: []string{"nope"}}) })
+	assert.Panics(t, func() { Slice([]int{1}).ToCSV(&buf, nil) })
+}
+
+func TestSliceOfMapsToCSV(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"id": 1, "tags": "a"},
+		nil,
+		map[string]interface{}{"id": 2, "name": "bob"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Slice(rows).ToCSV(&buf, nil))
+	assert.Equal(t, "id,name,tags\n1,,a\n,,\n2,bob,\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Slice(rows).ToCSV(&buf, &CSVOptions{Columns: []string{"name", "missing"}}))
+	assert.Equal(t, "name,missing\n,\n,\nbob,\n", buf.String())
+}
+
+func TestSliceFromCSV(t *testing.T) {
+	data := "NAME,age,score,joined,active,extra\n" +
+		"ann,31,9.5,2016-10-03T00:00:00Z,true,x\n" +
+		"bob,,7,,,\n"
+
+	var rows []testReportRow
+	ts, err := SliceFromCSV(strings.NewReader(data), &rows, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ts.Len())
+	assert.Equal(t, rows, ts.Value())
+	assert.Equal(t, "ann", rows[0].Name)
+	assert.Equal(t, 9.5, rows[0].Score)
+	assert.Equal(t, time.Date(2016, 10, 3, 0, 0, 0, 0, time.UTC), rows[0].Joined)
+	assert.True(t, *rows[0].Active)
+	assert.Equal(t, 0, rows[1].Age)
+	assert.Nil(t, rows[1].Active)
+
+	var ptrs []*testReportRow
+	_, err = SliceFromCSV(strings.NewReader("bob\t42\n"), &ptrs, &CSVOptions{
+		Comma:    '\t',
+		NoHeader: true,
+		Columns:  []string{"name", "age"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, ptrs[0].Age)
+
+	_, err = SliceFromCSV(strings.NewReader(data), &rows, &CSVOptions{Columns: []string{"age"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", rows[0].Name)
+	assert.Equal(t, 31, rows[0].Age)
+
+	_, err = SliceFromCSV(strings.NewReader("name,age\nann,31\nbob,old\n"), &rows, nil)
+	assert.EqualError(t, err, `tricks: SliceFromCSV: row 3, column 2 (age): can't convert "old" to int`)
+	csvErr, ok := err.(*CSVError)
+	assert.True(t, ok)
+	assert.Equal(t, 3, csvErr.Row)
+
+	ts, err = SliceFromCSV(strings.NewReader(""), &rows, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ts.Len())
+
+	assert.Panics(t, func() { SliceFromCSV(strings.NewReader(data), rows, nil) })
+	assert.Panics(t, func() { SliceFromCSV(strings.NewReader(data), &[]int{}, nil) })
+
+	// Maps have no fields to name the columns by.
+	var maps []map[string]string
+	_, err = SliceFromCSV(strings.NewReader("ann,31\n"), &maps, &CSVOptions{NoHeader: true, Columns: []string{"name", "age"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "ann", "age": "31"}}, maps)
+	assert.PanicsWithValue(t, "tricks: SliceFromCSV: Columns are needed to read maps without a header", func() {
+		SliceFromCSV(strings.NewReader("ann,31\n"), &maps, &CSVOptions{NoHeader: true})
+	})
+}
+
+func TestSliceOfMapsFromCSV(t *testing.T) {
+	var rows []map[string]int
+	_, err := SliceFromCSV(strings.NewReader("a,b\n1,2\n3,\n"), &rows, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]int{{"a": 1, "b": 2}, {"a": 3}}, rows)
+
+	// A round trip through CSV and back.
+	var buf bytes.Buffer
+	assert.NoError(t, Slice(rows).ToCSV(&buf, nil))
+	var back []map[string]int
+	_, err = SliceFromCSV(&buf, &back, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, rows, back)
+}
@@ -0,0 +1,277 @@
+package tricks
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A DecodeError describes a value that couldn't be decoded into a field.
+type DecodeError struct {
+	Field string // the path to the field, e.g. "Address.Zip"
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return "tricks: Decode: field " + e.Field + ": " + e.Err.Error()
+}
+
+// Decode fills the struct pointed to by dst from the entries of src, which is
+// a map with string keys (or a TrickMap of one), such as JSON decoded into a
+// map[string]interface{}. Keys are matched to fields by their `json` tags, the
+// same way ToMap names them, falling back to a case-insensitive match. Keys
+// with no matching field are ignored, as are fields with no matching key.
+//
+// Values are converted to the field types as needed: numbers convert between
+// types as long as they fit, strings are parsed into numbers and bools (and
+// into any type implementing encoding.TextUnmarshaler, like time.Time), nested
+// maps decode into nested structs and maps, and slices convert element by
+// element. If a value can't be converted, Decode returns a *DecodeError.
+func Decode(src, dst interface{}) error {
+	s := reflect.ValueOf(src)
+	if s.IsValid() && s.Type() == typeTrickMap {
+		s = reflect.Value(src.(TrickMap))
+	}
+	if s.Kind() != reflect.Map || s.Type().Key().Kind() != reflect.String {
+		panic("tricks: Decode: source is not a map with string keys")
+	}
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() || d.Elem().Kind() != reflect.Struct {
+		panic("tricks: Decode: destination is not a pointer to a struct")
+	}
+	return decodeStruct(d.Elem(), s, "")
+}
+
+var (
+	typeTrickMap        = reflect.TypeOf((*TrickMap)(nil)).Elem()                 // TrickMap
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem() // encoding.TextUnmarshaler
+)
+
+func decodeStruct(dst, src reflect.Value, prefix string) error {
+	fields := structFields(dst.Type(), "json")
+	for _, key := range src.MapKeys() {
+		f := matchField(fields, key.String())
+		if f == nil {
+			continue
+		}
+		path := prefix + f.name
+		field, err := allocFieldByIndex(dst, f.index)
+		if err == nil {
+			err = convertInto(field, src.MapIndex(key), path+".")
+		}
+		if err != nil {
+			return wrapDecodeError(path, err)
+		}
+	}
+	return nil
+}
+
+func matchField(fields []taggedField, key string) *taggedField {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// convertInto sets dst to src, converting as needed. Nested errors are wrapped
+// in a *DecodeError, with field paths starting from prefix.
+func convertInto(dst, src reflect.Value, prefix string) error {
+	if src.Kind() == reflect.Interface {
+		src = src.Elem()
+	}
+	if src.IsValid() && src.Type() == typeTrickMap {
+		src = reflect.Value(src.Interface().(TrickMap))
+	}
+	if src.IsValid() && src.Type() == typeTrickSlice {
+		src = reflect.Value(src.Interface().(TrickSlice))
+	}
+	if !src.IsValid() { // nil
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	// Strings into anything that can unmarshal itself from text.
+	if src.Kind() == reflect.String && reflect.PtrTo(dst.Type()).Implements(typeTextUnmarshaler) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(src.String()))
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if isNil(src) {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		elem := reflect.New(dst.Type().Elem())
+		if err := convertInto(elem.Elem(), src, prefix); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt(src)
+		if !ok || dst.OverflowInt(n) {
+			return convertError(src, dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := toUint(src)
+		if !ok || dst.OverflowUint(n) {
+			return convertError(src, dst.Type())
+		}
+		dst.SetUint(n)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case isNumber(src):
+			dst.SetFloat(toFloat(src))
+			return nil
+		case src.Kind() == reflect.String:
+			f, err := strconv.ParseFloat(src.String(), dst.Type().Bits())
+			if err != nil {
+				return convertError(src, dst.Type())
+			}
+			dst.SetFloat(f)
+			return nil
+		}
+
+	case reflect.Bool:
+		switch src.Kind() {
+		case reflect.Bool:
+			dst.SetBool(src.Bool())
+			return nil
+		case reflect.String:
+			b, err := strconv.ParseBool(src.String())
+			if err != nil {
+				return convertError(src, dst.Type())
+			}
+			dst.SetBool(b)
+			return nil
+		}
+
+	case reflect.String:
+		if src.Kind() == reflect.String {
+			dst.SetString(src.String())
+			return nil
+		}
+
+	case reflect.Struct:
+		if src.Kind() == reflect.Map && src.Type().Key().Kind() == reflect.String {
+			return decodeStruct(dst, src, prefix)
+		}
+
+	case reflect.Map:
+		if src.Kind() == reflect.Map {
+			out := reflect.MakeMap(dst.Type())
+			key := reflect.New(dst.Type().Key()).Elem()
+			val := reflect.New(dst.Type().Elem()).Elem()
+			for _, k := range src.MapKeys() {
+				key.Set(reflect.Zero(key.Type()))
+				val.Set(reflect.Zero(val.Type()))
+				path := fmt.Sprint(prefix, k.Interface())
+				if err := convertInto(key, k, path+"."); err != nil {
+					return wrapDecodeError(path, err)
+				}
+				if err := convertInto(val, src.MapIndex(k), path+"."); err != nil {
+					return wrapDecodeError(path, err)
+				}
+				out.SetMapIndex(key, val)
+			}
+			dst.Set(out)
+			return nil
+		}
+
+	case reflect.Slice:
+		if src.Kind() == reflect.Slice || src.Kind() == reflect.Array {
+			out := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+			for i := 0; i < src.Len(); i++ {
+				path := prefix + strconv.Itoa(i)
+				if err := convertInto(out.Index(i), src.Index(i), path+"."); err != nil {
+					return wrapDecodeError(path, err)
+				}
+			}
+			dst.Set(out)
+			return nil
+		}
+
+	case reflect.Array:
+		if (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && src.Len() == dst.Len() {
+			for i := 0; i < src.Len(); i++ {
+				path := prefix + strconv.Itoa(i)
+				if err := convertInto(dst.Index(i), src.Index(i), path+"."); err != nil {
+					return wrapDecodeError(path, err)
+				}
+			}
+			return nil
+		}
+	}
+
+	return convertError(src, dst.Type())
+}
+
+func wrapDecodeError(path string, err error) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	return &DecodeError{path, err}
+}
+
+func convertError(src reflect.Value, typ reflect.Type) error {
+	switch {
+	case src.Kind() == reflect.String:
+		return fmt.Errorf("can't convert %q to %s", src.String(), typ)
+	case isNumber(src):
+		return fmt.Errorf("can't convert %v to %s", src.Interface(), typ)
+	}
+	return fmt.Errorf("can't convert %s to %s", src.Type(), typ)
+}
+
+func toInt(src reflect.Value) (int64, bool) {
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return src.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := int64(src.Uint())
+		return n, n >= 0
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		return int64(f), f == float64(int64(f))
+	case reflect.String:
+		n, err := strconv.ParseInt(src.String(), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toUint(src reflect.Value) (uint64, bool) {
+	switch src.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return src.Uint(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := src.Int()
+		return uint64(n), n >= 0
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		return uint64(f), f >= 0 && f == float64(uint64(f))
+	case reflect.String:
+		n, err := strconv.ParseUint(src.String(), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
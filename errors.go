@@ -0,0 +1,24 @@
+package tricks
+
+import "fmt"
+
+// DuplicateKeyError is returned when an operation that builds a map finds two
+// values for the same key and cannot choose between them.
+type DuplicateKeyError struct {
+	Op  string      // the operation that failed, e.g. "slice.KeyByUnique"
+	Key interface{} // the duplicated key
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("tricks: %s: duplicate key %v", e.Op, e.Key)
+}
+
+// MissingKeysError is returned when keys that were asked for are not in a map.
+type MissingKeysError struct {
+	Op   string        // the operation that failed, e.g. "map.OnlyStrict"
+	Keys []interface{} // the missing keys
+}
+
+func (e *MissingKeysError) Error() string {
+	return fmt.Sprintf("tricks: %s: missing keys %v", e.Op, e.Keys)
+}
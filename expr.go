@@ -0,0 +1,211 @@
+package tricks
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A TrickExpr is an expression over the fields of a struct, or the keys of a
+// map, that can be passed to slice methods in place of a callback. It stands
+// in for:
+//
+//	func(T) bool         in Filter, Any, All, None, One, Many and Count
+//	func(T) interface{}  in Map, GroupBy and CountBy, giving the expression's value
+//	func(a, b T) bool    in SortBy, MinBy and MaxBy, comparing the expression's values
+//
+// This is handy when the callback isn't known until run time, such as when
+// sorting and filtering by fields named in a request.
+type TrickExpr struct {
+	src  string
+	eval queryExpr
+	desc bool
+}
+
+// Expr parses an expression, like `age >= 18 && country == 'NZ'`, panicking
+// if it isn't valid. The expressions are those of queries (see ParseQuery),
+// except that names, like age and country, are fields of the element: struct
+// fields are matched by their `json` tags, or else by name, ignoring case, the
+// same way Decode does it; and map values by their keys. Nested fields are
+// separated by dots, like address.city. Strings may be quoted with single
+// quotes, as well as double, and a string compared with a time.Time (or any
+// other encoding.TextUnmarshaler) is converted to one first.
+//
+// For example:
+//
+//	adults := tricks.Slice(users).Filter(tricks.Expr("age >= 18 && country == 'NZ'"))
+//	newest := tricks.Slice(users).SortBy(tricks.Expr("createdAt").Desc())
+//
+// If the expression can't be worked out for an element, such as when a struct
+// has no field of the given name, or values can't be compared, the method it
+// was passed to panics.
+func Expr(expr string) *TrickExpr {
+	e, err := ParseExpr(expr)
+	if err != nil {
+		panic(err.Error())
+	}
+	return e
+}
+
+// ParseExpr is like Expr, but returns a *QuerySyntaxError if the expression
+// isn't valid, rather than panicking. Use it for expressions that come from
+// users.
+func ParseExpr(expr string) (*TrickExpr, error) {
+	toks, err := lexQuery(expr)
+	if err == nil {
+		p := &queryParser{src: expr, toks: toks, expr: true}
+		var eval queryExpr
+		if eval, err = p.parseExpr(); err == nil {
+			if t := p.peek(); t.kind != tokEOF {
+				err = p.errorf(t.pos, "unexpected %s after expression", t)
+			} else {
+				return &TrickExpr{expr, eval, false}, nil
+			}
+		}
+	}
+	if e, ok := err.(*QuerySyntaxError); ok {
+		e.op = "Expr"
+	}
+	return nil, err
+}
+
+// String returns the expression as it was written.
+func (e *TrickExpr) String() string {
+	return e.src
+}
+
+// Desc returns a copy of the expression that sorts in descending order when
+// passed to SortBy, MinBy or MaxBy.
+func (e *TrickExpr) Desc() *TrickExpr {
+	return &TrickExpr{e.src, e.eval, !e.desc}
+}
+
+// value works out the expression for x, panicking if it can't.
+func (e *TrickExpr) value(x reflect.Value) interface{} {
+	v, err := e.eval(x.Interface())
+	if err != nil {
+		if qe, ok := err.(*QueryError); ok {
+			qe.op = "Expr"
+		}
+		panic(err.Error())
+	}
+	return v
+}
+
+// less compares the expression for a and b.
+func (e *TrickExpr) less(a, b reflect.Value) bool {
+	x, y := e.value(a), e.value(b)
+	c, ok := compareValues(reflect.ValueOf(x), reflect.ValueOf(y))
+	if !ok {
+		err := &QueryError{Query: e.src, Msg: fmt.Sprintf("can't compare %s with %s", queryKind(x), queryKind(y)), op: "Expr"}
+		panic(err.Error())
+	}
+	if e.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+type exprUse int
+
+const (
+	exprTest  exprUse = iota // func(T) bool
+	exprValue                // func(T) interface{}
+	exprKey                  // func(T) interface{}, giving a map key
+	exprLess                 // func(a, b T) bool
+)
+
+var typeBool = reflect.TypeOf(false)
+
+// exprCallback returns fn as it is, unless it's a *TrickExpr, in which case it
+// returns the kind of func that use needs, for the elements of sliceType.
+func exprCallback(fn interface{}, sliceType reflect.Type, use exprUse) interface{} {
+	e, ok := fn.(*TrickExpr)
+	if !ok {
+		return fn
+	}
+	elem := sliceType.Elem()
+	var in, out []reflect.Type
+	var call func([]reflect.Value) []reflect.Value
+	switch use {
+	case exprTest:
+		in, out = []reflect.Type{elem}, []reflect.Type{typeBool}
+		call = func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(truthy(e.value(args[0])))}
+		}
+	case exprValue, exprKey:
+		in, out = []reflect.Type{elem}, []reflect.Type{typeInterface}
+		call = func(args []reflect.Value) []reflect.Value {
+			key := e.value(args[0])
+			if use == exprKey && !hashable(key) {
+				err := &QueryError{Query: e.src, Msg: fmt.Sprintf("can't group by %s", queryKind(key)), op: "Expr"}
+				panic(err.Error())
+			}
+			return []reflect.Value{reflect.ValueOf(&key).Elem()}
+		}
+	case exprLess:
+		in, out = []reflect.Type{elem, elem}, []reflect.Type{typeBool}
+		call = func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(e.less(args[0], args[1]))}
+		}
+	}
+	return reflect.MakeFunc(reflect.FuncOf(in, out, false), call).Interface()
+}
+
+// hashable reports whether v can be used as a map key. Structs and arrays can
+// have comparable types, but still hold slices or maps in interfaces.
+func hashable(v interface{}) (ok bool) {
+	if v == nil {
+		return true
+	}
+	if !reflect.TypeOf(v).Comparable() {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return v == v
+}
+
+// fieldPath makes an expression for the path segs (starting at pos) for Expr.
+// Unlike the paths of queries, struct fields are matched by their `json` tags
+// or case-insensitively, and a struct without the field is an error. Pointers
+// are followed, so the value is what the field points to, or nil.
+func (p *queryParser) fieldPath(segs []string, pos int) queryExpr {
+	return func(x interface{}) (interface{}, error) {
+		v := reflect.ValueOf(x)
+		for _, seg := range segs {
+			c, _ := unwrapPath(v)
+			switch c.Kind() {
+			case reflect.Struct:
+				f := matchField(structFields(c.Type(), "json"), seg)
+				if f == nil {
+					return nil, p.runError(pos, "no field %q in %s", seg, c.Type())
+				}
+				v = fieldByIndex(c, f.index)
+			case reflect.Map:
+				key, err := pathKey(seg, c.Type().Key())
+				if err != nil {
+					return nil, nil
+				}
+				v = c.MapIndex(key)
+			case reflect.Slice, reflect.Array:
+				i, err := pathIndex(seg, c.Len())
+				if err != nil {
+					return nil, nil
+				}
+				v = c.Index(i)
+			default:
+				return nil, nil
+			}
+			if !v.IsValid() {
+				return nil, nil
+			}
+		}
+		if v = indirect(v); !v.IsValid() {
+			return nil, nil
+		}
+		return v.Interface(), nil
+	}
+}
@@ -0,0 +1,156 @@
+package tricks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMember struct {
+	Name      string    `json:"name"`
+	Age       int       `json:"age"`
+	Country   string    `json:"country"`
+	CreatedAt time.Time `json:"createdAt"`
+	Address   *testAddress
+	Score     *int     `json:"score"`
+	Tags      []string `json:"tags"`
+}
+
+func testMembers() []testMember {
+	day := func(d int) time.Time { return time.Date(2016, 10, d, 0, 0, 0, 0, time.UTC) }
+	score := func(n int) *int { return &n }
+	return []testMember{
+		{"ann", 31, "NZ", day(3), &testAddress{"Auckland", "NZ"}, score(3), []string{"admin"}},
+		{"bob", 17, "NZ", day(1), nil, nil, nil},
+		{"cat", 27, "AU", day(2), &testAddress{"Sydney", "AU"}, score(5), []string{"beta"}},
+		{"dan", 45, "NZ", day(4), &testAddress{"Wellington", "NZ"}, score(3), nil},
+	}
+}
+
+func memberNames(users interface{}) []string {
+	return Slice(users).Map(func(u testMember) string { return u.Name }).Value().([]string)
+}
+
+func TestExprFilter(t *testing.T) {
+	users := Slice(testMembers())
+	adults := users.Filter(Expr("age >= 18 && country == 'NZ'"))
+	assert.Equal(t, []string{"ann", "dan"}, memberNames(adults.Value()))
+
+	// Fields can be named by Go name, json tag, or either ignoring case, and
+	// with or without a leading dot.
+	for _, expr := range []string{"Age < 18", "AGE < 18", ".age < 18"} {
+		assert.Equal(t, []string{"bob"}, memberNames(users.Filter(Expr(expr)).Value()), expr)
+	}
+
+	// A nil pointer along the way gives null.
+	assert.Equal(t, []string{"bob"}, memberNames(users.Filter(Expr("address.city == null")).Value()))
+	assert.Equal(t, []string{"cat"}, memberNames(users.Filter(Expr(`Address.City == "Sydney"`)).Value()))
+
+	assert.True(t, users.Any(Expr("name == 'cat'")))
+	assert.False(t, users.All(Expr("age > 20")))
+	assert.True(t, users.None(Expr("country == 'US'")))
+	assert.True(t, users.One(Expr("age < 18")))
+	assert.Equal(t, 3, users.Count(Expr("country == 'NZ'")))
+}
+
+func TestExprSortBy(t *testing.T) {
+	sorted := Slice(testMembers()).SortBy(Expr("age"))
+	assert.Equal(t, []string{"bob", "cat", "ann", "dan"}, memberNames(sorted.Value()))
+
+	sorted = Slice(testMembers()).SortBy(Expr("createdAt").Desc())
+	assert.Equal(t, []string{"dan", "ann", "cat", "bob"}, memberNames(sorted.Value()))
+	assert.Equal(t, "createdAt", Expr("createdAt").Desc().String())
+
+	oldest := Slice(testMembers()).MaxBy(Expr("age"))
+	assert.Equal(t, "dan", oldest.(testMember).Name)
+	newest := Slice(testMembers()).MinBy(Expr("createdAt").Desc())
+	assert.Equal(t, "dan", newest.(testMember).Name)
+
+	// Strings are read as times when compared with them.
+	later := Slice(testMembers()).Filter(Expr("createdAt > '2016-10-02T12:00:00Z'"))
+	assert.Equal(t, []string{"ann", "dan"}, memberNames(later.Value()))
+}
+
+func TestExprGroupBy(t *testing.T) {
+	groups := Slice(testMembers()).GroupBy(Expr("country"))
+	assert.Equal(t, map[interface{}][]testMember{
+		"NZ": {testMembers()[0], testMembers()[1], testMembers()[3]},
+		"AU": {testMembers()[2]},
+	}, groups.Value())
+
+	counts := Slice(testMembers()).CountBy(Expr("age >= 30"))
+	assert.Equal(t, map[interface{}]int{true: 2, false: 2}, counts.Value())
+
+	ages := Slice(testMembers()).Map(Expr("age + 1"))
+	assert.Equal(t, []interface{}{32.0, 18.0, 28.0, 46.0}, ages.Value())
+}
+
+func TestExprPointers(t *testing.T) {
+	users := Slice(testMembers())
+	assert.Equal(t, []string{"ann", "dan"}, memberNames(users.Filter(Expr("score == 3")).Value()))
+	assert.Equal(t, []string{"bob"}, memberNames(users.Filter(Expr("score == null")).Value()))
+
+	sorted := Slice(testMembers()).SortBy(Expr("score").Desc())
+	assert.Equal(t, []string{"cat", "ann", "dan", "bob"}, memberNames(sorted.Value()))
+
+	counts := users.CountBy(Expr("score"))
+	assert.Equal(t, map[interface{}]int{3: 2, 5: 1, nil: 1}, counts.Value())
+	assert.Equal(t, []interface{}{3, nil, 5, 3}, users.Map(Expr("score")).Value())
+}
+
+func TestExprMaps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"status": "active", "score": 3},
+		{"status": "closed", "score": 5},
+		{"status": "active"},
+	}
+	active := Slice(rows).Filter(Expr("status == 'active' && score > 1"))
+	assert.Equal(t, []map[string]interface{}{rows[0]}, active.Value())
+
+	sorted := Slice(rows).Copy().SortBy(Expr("score").Desc())
+	assert.Equal(t, []map[string]interface{}{rows[1], rows[0], rows[2]}, sorted.Value())
+}
+
+func TestExprErrors(t *testing.T) {
+	_, err := ParseExpr("age >= ")
+	assert.EqualError(t, err, "tricks: Expr: syntax error at column 8: expected an expression, found end of query")
+	_, err = ParseExpr("age >= 18 country")
+	assert.EqualError(t, err, `tricks: Expr: syntax error at column 11: unexpected "country" after expression`)
+	_, ok := err.(*QuerySyntaxError)
+	assert.True(t, ok)
+
+	assert.PanicsWithValue(t, "tricks: Expr: syntax error at column 7: expected an expression, found end of query", func() {
+		Expr("age >=")
+	})
+
+	users := Slice(testMembers())
+	assert.PanicsWithValue(t, `tricks: Expr: error at column 1: no field "agee" in tricks.testMember`, func() {
+		users.Filter(Expr("agee > 18"))
+	})
+	assert.PanicsWithValue(t, "tricks: Expr: error at column 6: can't compare a string with a number", func() {
+		users.Filter(Expr("name < 3"))
+	})
+
+	func() {
+		defer func() {
+			assert.Contains(t, recover(), "tricks: Expr: error at column 1: can't compare")
+		}()
+		Slice([]interface{}{"a", 1}).SortBy(Expr("."))
+	}()
+
+	// Slices can be values, but not keys.
+	assert.Equal(t, []interface{}{[]string{"admin"}, []string(nil), []string{"beta"}, []string(nil)},
+		users.Map(Expr("tags")).Value())
+	assert.PanicsWithValue(t, "tricks: Expr: error at column 1: can't group by a slice", func() {
+		users.GroupBy(Expr("tags"))
+	})
+	assert.PanicsWithValue(t, "tricks: Expr: error at column 1: can't group by a slice", func() {
+		users.CountBy(Expr("tags"))
+	})
+
+	// Funcs still have their types checked as usual.
+	assert.PanicsWithValue(t, "tricks: slice.Filter: invalid function type", func() {
+		users.Filter(func(int) bool { return true })
+	})
+}
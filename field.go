@@ -0,0 +1,136 @@
+package tricks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Fields are named by dotted paths like "Address.City". Each segment selects a
+// struct field by name (including promoted fields of embedded structs), or a
+// key of a map with string keys. Pointers and interfaces are followed along
+// the way.
+
+type fieldKey struct {
+	typ  reflect.Type
+	name string
+}
+
+type fieldInfo struct {
+	index []int
+	typ   reflect.Type
+	err   error
+}
+
+// Struct fields are looked up by name once per type, then cached.
+var fieldCache = struct {
+	sync.RWMutex
+	m map[fieldKey]fieldInfo
+}{m: make(map[fieldKey]fieldInfo)}
+
+func lookupField(typ reflect.Type, name string) fieldInfo {
+	key := fieldKey{typ, name}
+	fieldCache.RLock()
+	info, ok := fieldCache.m[key]
+	fieldCache.RUnlock()
+	if ok {
+		return info
+	}
+
+	f, ok := typ.FieldByName(name)
+	switch {
+	case !ok:
+		info.err = fmt.Errorf("no field %q in %s", name, typ)
+	case f.PkgPath != "":
+		info.err = fmt.Errorf("field %q of %s is not exported", name, typ)
+	default:
+		info.index, info.typ = f.Index, f.Type
+	}
+
+	fieldCache.Lock()
+	fieldCache.m[key] = info
+	fieldCache.Unlock()
+	return info
+}
+
+func splitFieldPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// fieldType returns the static type found at path, starting from typ. If the
+// path passes through an interface, the rest of it can't be checked until we
+// have a value, and the type is interface{}.
+func fieldType(typ reflect.Type, path []string) (reflect.Type, error) {
+	for _, name := range path {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		switch {
+		case typ.Kind() == reflect.Interface:
+			return typeInterface, nil
+		case typ.Kind() == reflect.Struct:
+			f := lookupField(typ, name)
+			if f.err != nil {
+				return nil, f.err
+			}
+			typ = f.typ
+		case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String:
+			typ = typ.Elem()
+		default:
+			return nil, fmt.Errorf("can't get field %q of %s", name, typ)
+		}
+	}
+	return typ, nil
+}
+
+// fieldValue returns the value found at path, starting from v. If a nil
+// pointer or missing map key is encountered along the way, the returned value
+// is invalid (the zero reflect.Value) and the error is nil.
+func fieldValue(v reflect.Value, path []string) (reflect.Value, error) {
+	for _, name := range path {
+		v = indirect(v)
+		switch {
+		case !v.IsValid():
+			return v, nil
+		case v.Kind() == reflect.Struct:
+			f := lookupField(v.Type(), name)
+			if f.err != nil {
+				return reflect.Value{}, f.err
+			}
+			v = fieldByIndex(v, f.index)
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		default:
+			return reflect.Value{}, fmt.Errorf("can't get field %q of %s", name, v.Type())
+		}
+	}
+	return v, nil
+}
+
+// indirect follows pointers and interfaces until it reaches a concrete value.
+// It returns an invalid value if it hits a nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// fieldByIndex is like v.FieldByIndex, but returns an invalid value instead of
+// panicking when it passes through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			v = indirect(v)
+			if !v.IsValid() {
+				return v
+			}
+		}
+		v = v.Field(x)
+	}
+	return v
+}
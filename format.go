@@ -0,0 +1,91 @@
+package tricks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// String returns the underlying slice formatted with %v.
+func (ts TrickSlice) String() string {
+	return fmt.Sprintf("%v", ts)
+}
+
+// Format lets the fmt package print the underlying slice, rather than the
+// insides of the TrickSlice. All the usual verbs and flags work as they would
+// on the slice itself, except that %s prints the result of String, and %+v adds
+// the slice's type, like `[]int([1 2 3])`.
+func (ts TrickSlice) Format(f fmt.State, verb rune) {
+	formatValue(f, verb, reflect.Value(ts), ts)
+}
+
+// String returns the underlying map formatted with %v, with its keys sorted.
+func (tm TrickMap) String() string {
+	return fmt.Sprintf("%v", tm)
+}
+
+// Format lets the fmt package print the underlying map, rather than the
+// insides of the TrickMap. The keys are sorted as for Entries, so the output is
+// the same every time. As for TrickSlice, %s prints the result of String, and
+// %+v adds the map's type, like `map[string]int(map[a:1])`.
+func (tm TrickMap) Format(f fmt.State, verb rune) {
+	formatValue(f, verb, reflect.Value(tm), tm)
+}
+
+func formatValue(f fmt.State, verb rune, v reflect.Value, s fmt.Stringer) {
+	if !v.IsValid() {
+		io.WriteString(f, "<nil>")
+		return
+	}
+	format := formatDirective(f, verb)
+	if verb == 's' {
+		// Like any fmt.Stringer, print the result of String.
+		fmt.Fprintf(f, format, s.String())
+		return
+	}
+	if verb != 'v' || f.Flag('#') || v.Kind() != reflect.Map {
+		if verb == 'v' && f.Flag('+') {
+			fmt.Fprintf(f, "%T("+format+")", v.Interface(), v.Interface())
+			return
+		}
+		fmt.Fprintf(f, format, v.Interface())
+		return
+	}
+
+	if f.Flag('+') {
+		fmt.Fprintf(f, "%T(", v.Interface())
+	}
+	io.WriteString(f, "map[")
+	for i, key := range sortedKeys(v) {
+		if i > 0 {
+			io.WriteString(f, " ")
+		}
+		fmt.Fprintf(f, format+":"+format, key.Interface(), v.MapIndex(key).Interface())
+	}
+	io.WriteString(f, "]")
+	if f.Flag('+') {
+		io.WriteString(f, ")")
+	}
+}
+
+// formatDirective rebuilds the directive (like "%-8.2f") that f was made from.
+func formatDirective(f fmt.State, verb rune) string {
+	var buf bytes.Buffer
+	buf.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			buf.WriteRune(flag)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		buf.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		buf.WriteByte('.')
+		buf.WriteString(strconv.Itoa(p))
+	}
+	buf.WriteRune(verb)
+	return buf.String()
+}
@@ -0,0 +1,49 @@
+package tricks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceFormat(t *testing.T) {
+	ts := Slice([]int{1, 2, 3})
+	assert.Equal(t, "[1 2 3]", ts.String())
+	assert.Equal(t, "[1 2 3]", fmt.Sprint(ts))
+	assert.Equal(t, "[1 2 3]", fmt.Sprintf("%v", ts))
+	assert.Equal(t, "[]int([1 2 3])", fmt.Sprintf("%+v", ts))
+	assert.Equal(t, "[]int{1, 2, 3}", fmt.Sprintf("%#v", ts))
+	assert.Equal(t, "[001 002 003]", fmt.Sprintf("%03d", ts))
+	assert.Equal(t, "tricks.TrickSlice", fmt.Sprintf("%T", ts))
+
+	users := Slice([]testUser{{1, "ann"}})
+	assert.Equal(t, "[{1 ann}]", users.String())
+	assert.Equal(t, "[]tricks.testUser([{ID:1 Name:ann}])", fmt.Sprintf("%+v", users))
+
+	nested := Slice([]interface{}{Slice([]string{"a"}), Map(map[string]int{"b": 1})})
+	assert.Equal(t, "[[a] map[b:1]]", nested.String())
+
+	assert.Equal(t, "<nil>", TrickSlice{}.String())
+}
+
+func TestMapFormat(t *testing.T) {
+	tm := Map(map[string]int{"c": 3, "a": 1, "b": 2})
+	assert.Equal(t, "map[a:1 b:2 c:3]", tm.String())
+	assert.Equal(t, "map[a:1 b:2 c:3]", fmt.Sprintf("%v", tm))
+	assert.Equal(t, "map[string]int(map[a:1 b:2 c:3])", fmt.Sprintf("%+v", tm))
+	assert.Equal(t, "got map[a:1 b:2 c:3]", fmt.Sprintf("got %s", tm.Only("a", "b", "c")))
+
+	byID := Map(map[int][]testUser{2: {{2, "bob"}}, 1: {{1, "ann"}}})
+	assert.Equal(t, "map[int][]tricks.testUser(map[1:[{ID:1 Name:ann}] 2:[{ID:2 Name:bob}]])", fmt.Sprintf("%+v", byID))
+
+	assert.Equal(t, "<nil>", TrickMap{}.String())
+}
+
+func TestFormatStringVerbs(t *testing.T) {
+	words := Slice([]string{"a b", "c"})
+	assert.Equal(t, "[a b c]", fmt.Sprintf("%s", words))
+	assert.Equal(t, `["a b" "c"]`, fmt.Sprintf("%q", words))
+	assert.Equal(t, "   [a b c]", fmt.Sprintf("%10s", words))
+	assert.Equal(t, "map[x:[1] y:[]]", fmt.Sprintf("%s", Map(map[string][]int{"y": {}, "x": {1}})))
+}
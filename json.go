@@ -0,0 +1,104 @@
+package tricks
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	typeInterfaceSlice = reflect.TypeOf([]interface{}(nil))
+	typeStringMap      = reflect.TypeOf(map[string]interface{}(nil))
+)
+
+// MarshalJSON encodes the underlying slice, so that a TrickSlice can be put
+// straight into a response, or a struct that will become one.
+func (ts TrickSlice) MarshalJSON() ([]byte, error) {
+	return marshalValue(reflect.Value(ts))
+}
+
+// MarshalJSON encodes the underlying map, so that a TrickMap can be put
+// straight into a response, or a struct that will become one.
+func (tm TrickMap) MarshalJSON() ([]byte, error) {
+	return marshalValue(reflect.Value(tm))
+}
+
+func marshalValue(v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v.Interface())
+}
+
+// UnmarshalJSON decodes a JSON array into a new slice of the same type as the
+// one ts already holds, or a []interface{} if it doesn't hold one yet (as for
+// the zero TrickSlice).
+func (ts *TrickSlice) UnmarshalJSON(data []byte) error {
+	out, err := unmarshalValue(data, reflect.Value(*ts), typeInterfaceSlice)
+	if err == nil {
+		*ts = TrickSlice(out)
+	}
+	return err
+}
+
+// UnmarshalJSON decodes a JSON object into a new map of the same type as the
+// one tm already holds, or a map[string]interface{} if it doesn't hold one yet
+// (as for the zero TrickMap).
+func (tm *TrickMap) UnmarshalJSON(data []byte) error {
+	out, err := unmarshalValue(data, reflect.Value(*tm), typeStringMap)
+	if err == nil {
+		*tm = TrickMap(out)
+	}
+	return err
+}
+
+func unmarshalValue(data []byte, v reflect.Value, def reflect.Type) (reflect.Value, error) {
+	typ := def
+	if v.IsValid() {
+		typ = v.Type()
+	}
+	ptr := reflect.New(typ)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+// SliceFromJSON decodes a JSON array into the slice that ptrToSlice points to,
+// and returns it as a TrickSlice. If ptrToSlice is nil, the array is decoded
+// into a []interface{}.
+//
+// For example:
+//
+//	var users []User
+//	active, err := tricks.SliceFromJSON(data, &users)
+func SliceFromJSON(data []byte, ptrToSlice interface{}) (TrickSlice, error) {
+	ptr := jsonTarget("SliceFromJSON", ptrToSlice, reflect.Slice, typeInterfaceSlice)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return TrickSlice{}, err
+	}
+	return TrickSlice(ptr.Elem()), nil
+}
+
+// MapFromJSON decodes a JSON object into the map that ptrToMap points to, and
+// returns it as a TrickMap. If ptrToMap is nil, the object is decoded into a
+// map[string]interface{}.
+func MapFromJSON(data []byte, ptrToMap interface{}) (TrickMap, error) {
+	ptr := jsonTarget("MapFromJSON", ptrToMap, reflect.Map, typeStringMap)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return TrickMap{}, err
+	}
+	return TrickMap(ptr.Elem()), nil
+}
+
+// jsonTarget returns target as a pointer to something of the given kind, or a
+// new pointer to def if target is nil.
+func jsonTarget(context string, target interface{}, kind reflect.Kind, def reflect.Type) reflect.Value {
+	if target == nil {
+		return reflect.New(def)
+	}
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != kind {
+		panic("tricks: " + context + ": input is not a pointer to a " + kind.String())
+	}
+	return ptr
+}
@@ -0,0 +1,85 @@
+package tricks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testResponse struct {
+	Items TrickSlice `json:"items"`
+	Meta  TrickMap   `json:"meta"`
+}
+
+func TestMarshalJSON(t *testing.T) {
+	resp := testResponse{
+		Items: Slice([]int{3, 1, 2}).Sort(),
+		Meta:  Map(map[string]int{"total": 3}),
+	}
+	data, err := json.Marshal(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"items":[1,2,3],"meta":{"total":3}}`, string(data))
+
+	data, err = json.Marshal(testResponse{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"items":null,"meta":null}`, string(data))
+
+	// Struct.ToMap leaves them alone, since they marshal themselves.
+	m := Struct(resp).ToMap().Value().(map[string]interface{})
+	assert.Equal(t, resp.Items, m["items"])
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var resp testResponse
+	err := json.Unmarshal([]byte(`{"items":[1,"a"],"meta":{"x":true}}`), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0, "a"}, resp.Items.Value())
+	assert.Equal(t, map[string]interface{}{"x": true}, resp.Meta.Value())
+
+	// A TrickSlice or TrickMap that already holds a value decodes into its type.
+	ts := Slice([]int{})
+	assert.NoError(t, json.Unmarshal([]byte(`[4,5]`), &ts))
+	assert.Equal(t, []int{4, 5}, ts.Value())
+	assert.Error(t, json.Unmarshal([]byte(`["x"]`), &ts))
+	assert.Equal(t, []int{4, 5}, ts.Value())
+
+	tm := Map(map[int]bool{})
+	assert.NoError(t, json.Unmarshal([]byte(`{"1":true}`), &tm))
+	assert.Equal(t, map[int]bool{1: true}, tm.Value())
+}
+
+func TestSliceFromJSON(t *testing.T) {
+	var users []testUser
+	ts, err := SliceFromJSON([]byte(`[{"ID":1,"Name":"ann"},{"ID":2,"Name":"bob"}]`), &users)
+	assert.NoError(t, err)
+	assert.Equal(t, []testUser{{1, "ann"}, {2, "bob"}}, users)
+	assert.Equal(t, []string{"ann", "bob"}, ts.Pluck("Name").Value())
+
+	ts, err = SliceFromJSON([]byte(`[1,"a",null]`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0, "a", nil}, ts.Value())
+
+	_, err = SliceFromJSON([]byte(`{}`), &users)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { SliceFromJSON([]byte(`[]`), users) })
+	assert.Panics(t, func() { SliceFromJSON([]byte(`[]`), &map[string]int{}) })
+}
+
+func TestMapFromJSON(t *testing.T) {
+	var limits map[string]int
+	tm, err := MapFromJSON([]byte(`{"cpu":2,"mem":512}`), &limits)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"cpu": 2, "mem": 512}, limits)
+	assert.Equal(t, []string{"cpu", "mem"}, tm.Keys().Sort().Value())
+
+	tm, err = MapFromJSON([]byte(`{"a":[1]}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{1.0}}, tm.Value())
+
+	_, err = MapFromJSON([]byte(`[1]`), nil)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MapFromJSON([]byte(`{}`), &[]int{}) })
+}
@@ -1,6 +1,9 @@
 package tricks
 
-import "reflect"
+import (
+	"reflect"
+	"sort"
+)
 
 type TrickMap reflect.Value
 
@@ -93,6 +96,55 @@ func (tm TrickMap) Only(keys ...interface{}) TrickMap {
 	return TrickMap(out)
 }
 
+// OnlyStrict is like Only, but if any of the given keys are missing from the
+// map, it returns a *MissingKeysError listing them.
+func (tm TrickMap) OnlyStrict(keys ...interface{}) (TrickMap, error) {
+	v := reflect.Value(tm)
+	k := reflect.Value(Slice(keys...))
+
+	keyType := v.Type().Key()
+
+	out := reflect.MakeMap(v.Type())
+	var missing []interface{}
+	for i := 0; i < k.Len(); i++ {
+		key := k.Index(i)
+		if !key.Type().AssignableTo(keyType) {
+			panic("tricks: map.OnlyStrict: key doesn't match map's key type")
+		}
+		val := v.MapIndex(key)
+		if !val.IsValid() {
+			missing = append(missing, key.Interface())
+			continue
+		}
+		out.SetMapIndex(key, val)
+	}
+
+	if missing != nil {
+		return TrickMap{}, &MissingKeysError{"map.OnlyStrict", missing}
+	}
+	return TrickMap(out), nil
+}
+
+// Except returns a new map containing all but the given keys. Except accepts
+// the same arguments as Slice()
+func (tm TrickMap) Except(keys ...interface{}) TrickMap {
+	v := reflect.Value(tm)
+	k := reflect.Value(Slice(keys...))
+
+	keyType := v.Type().Key()
+
+	out := reflect.Value(tm.Copy())
+	for i := 0; i < k.Len(); i++ {
+		key := k.Index(i)
+		if !key.Type().AssignableTo(keyType) {
+			panic("tricks: map.Except: key doesn't match map's key type")
+		}
+		out.SetMapIndex(key, reflect.Value{}) // delete
+	}
+
+	return TrickMap(out)
+}
+
 // HasKeys returns true if the map has all of the given keys, else false.
 // HasKeys accepts the same arguments as Slice()
 func (tm TrickMap) HasKeys(keys ...interface{}) bool {
@@ -114,3 +166,84 @@ func (tm TrickMap) HasKeys(keys ...interface{}) bool {
 
 	return true
 }
+
+// sortedKeys returns the map's keys, sorted if they're numbers, strings, bools
+// or times. Otherwise they're left in whatever order the map gives them.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	for i := 1; i < len(keys); i++ {
+		if _, ok := compareValues(keys[0], keys[i]); !ok {
+			return keys
+		}
+	}
+	sort.Sort(sortableKeys(keys))
+	return keys
+}
+
+type sortableKeys []reflect.Value
+
+func (s sortableKeys) Len() int      { return len(s) }
+func (s sortableKeys) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s sortableKeys) Less(i, j int) bool {
+	c, _ := compareValues(s[i], s[j])
+	return c < 0
+}
+
+// valueOfType returns x as a reflect.Value that can be stored as a map's key
+// or value of type typ, panicking if it doesn't fit. what is "key" or "value".
+func valueOfType(context, what string, typ reflect.Type, x interface{}) reflect.Value {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() { // nil
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(typ)
+		}
+	} else if v.Type().AssignableTo(typ) {
+		return v
+	}
+	panic("tricks: " + context + ": " + what + " doesn't match map's " + what + " type")
+}
+
+// Invert returns a new map with the keys and values swapped (`map[V]K`). If
+// two keys share the same value, it returns a *DuplicateKeyError, since only
+// one of them could be kept; use InvertGrouped to keep them all.
+func (tm TrickMap) Invert() (TrickMap, error) {
+	out, err := invert("map.Invert", reflect.Value(tm))
+	return TrickMap(out), err
+}
+
+func invert(context string, v reflect.Value) (reflect.Value, error) {
+	valType := v.Type().Elem()
+	if !valType.Comparable() {
+		panic("tricks: " + context + ": value type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(valType, v.Type().Key()))
+	for _, key := range v.MapKeys() {
+		val := v.MapIndex(key)
+		if out.MapIndex(val).IsValid() {
+			return reflect.Value{}, &DuplicateKeyError{context, val.Interface()}
+		}
+		out.SetMapIndex(val, key)
+	}
+
+	return out, nil
+}
+
+// InvertGrouped returns a new map with the keys and values swapped, where each
+// value maps to a slice of all the keys that had it (`map[V][]K`). The keys in
+// each slice are sorted, if they're of a type that Sort handles.
+func (tm TrickMap) InvertGrouped() TrickMap {
+	v := reflect.Value(tm)
+	valType := v.Type().Elem()
+	if !valType.Comparable() {
+		panic("tricks: map.InvertGrouped: value type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(valType, reflect.SliceOf(v.Type().Key())))
+	for _, key := range sortedKeys(v) {
+		appendGroup(out, v.MapIndex(key), key)
+	}
+
+	return TrickMap(out)
+}
@@ -0,0 +1,88 @@
+package tricks
+
+import "reflect"
+
+// A BiMap is a one-to-one map that can be looked up in either direction, by
+// key or by value. Setting or deleting entries keeps both directions in step,
+// so each value only ever belongs to one key.
+type BiMap struct {
+	forward reflect.Value // map[K]V
+	inverse reflect.Value // map[V]K
+}
+
+// NewBiMap makes a BiMap holding a copy of the entries of anyMap, which may be
+// a map or a TrickMap. The values must be of a comparable type, and if two keys
+// share the same value, it returns a *DuplicateKeyError.
+func NewBiMap(anyMap interface{}) (*BiMap, error) {
+	v := mapValueOf("NewBiMap", anyMap)
+	inverse, err := invert("NewBiMap", v)
+	if err != nil {
+		return nil, err
+	}
+	return &BiMap{reflect.Value(TrickMap(v).Copy()), inverse}, nil
+}
+
+// Len returns the number of entries in the map.
+func (b *BiMap) Len() int {
+	return b.forward.Len()
+}
+
+// Get returns the value for key, and whether it was found.
+func (b *BiMap) Get(key interface{}) (interface{}, bool) {
+	k := valueOfType("bimap.Get", "key", b.forward.Type().Key(), key)
+	return lookup(b.forward, k)
+}
+
+// GetKey returns the key for value, and whether it was found.
+func (b *BiMap) GetKey(value interface{}) (interface{}, bool) {
+	v := valueOfType("bimap.GetKey", "value", b.forward.Type().Elem(), value)
+	return lookup(b.inverse, v)
+}
+
+func lookup(m, key reflect.Value) (interface{}, bool) {
+	val := m.MapIndex(key)
+	if !val.IsValid() {
+		return nil, false
+	}
+	return val.Interface(), true
+}
+
+// Set maps key to value. Any entry already holding the key, or the value, is
+// replaced.
+func (b *BiMap) Set(key, value interface{}) {
+	k := valueOfType("bimap.Set", "key", b.forward.Type().Key(), key)
+	v := valueOfType("bimap.Set", "value", b.forward.Type().Elem(), value)
+	b.delete(k, b.forward.MapIndex(k))
+	b.delete(b.inverse.MapIndex(v), v)
+	b.forward.SetMapIndex(k, v)
+	b.inverse.SetMapIndex(v, k)
+}
+
+// Delete removes the entry for key, if there is one.
+func (b *BiMap) Delete(key interface{}) {
+	k := valueOfType("bimap.Delete", "key", b.forward.Type().Key(), key)
+	b.delete(k, b.forward.MapIndex(k))
+}
+
+// DeleteValue removes the entry for value, if there is one.
+func (b *BiMap) DeleteValue(value interface{}) {
+	v := valueOfType("bimap.DeleteValue", "value", b.forward.Type().Elem(), value)
+	b.delete(b.inverse.MapIndex(v), v)
+}
+
+func (b *BiMap) delete(key, value reflect.Value) {
+	if key.IsValid() && value.IsValid() {
+		b.forward.SetMapIndex(key, reflect.Value{})
+		b.inverse.SetMapIndex(value, reflect.Value{})
+	}
+}
+
+// Map returns a copy of the map, from keys to values.
+func (b *BiMap) Map() TrickMap {
+	return TrickMap(b.forward).Copy()
+}
+
+// Inverse returns a copy of the inverted map, from values to keys.
+func (b *BiMap) Inverse() TrickMap {
+	return TrickMap(b.inverse).Copy()
+}
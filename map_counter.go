@@ -0,0 +1,115 @@
+package tricks
+
+import "reflect"
+
+// A Counter counts things, like the map[T]int that Tally returns, but with
+// methods to add to the counts and to combine counters. Keys that have never
+// been counted have a count of zero.
+type Counter struct {
+	m reflect.Value // map[K]int
+}
+
+// NewCounter makes a Counter holding a copy of the counts in anyMap, which must
+// be a map[K]int, or a TrickMap of one, such as Tally or CountBy returns. Pass
+// an empty map to start from scratch.
+func NewCounter(anyMap interface{}) *Counter {
+	v := mapValueOf("NewCounter", anyMap)
+	if v.Type().Elem() != typeInt {
+		panic("tricks: NewCounter: input is not a map of ints")
+	}
+	return &Counter{reflect.Value(TrickMap(v).Copy())}
+}
+
+// Counter counts the occurrences of each distinct element in the slice, the
+// same as Tally, but returns a Counter. The elements must be comparable.
+func (ts TrickSlice) Counter() *Counter {
+	_, counts := tally("slice.Counter", reflect.Value(ts))
+	return &Counter{counts}
+}
+
+// Len returns the number of keys counted.
+func (c *Counter) Len() int {
+	return c.m.Len()
+}
+
+// Get returns the count for key, or zero if it hasn't been counted.
+func (c *Counter) Get(key interface{}) int {
+	k := valueOfType("counter.Get", "key", c.m.Type().Key(), key)
+	if n := c.m.MapIndex(k); n.IsValid() {
+		return int(n.Int())
+	}
+	return 0
+}
+
+// Inc adds one to the count for each of the given keys, and returns the same
+// counter. Inc accepts the same arguments as Slice()
+func (c *Counter) Inc(keys ...interface{}) *Counter {
+	k := reflect.Value(Slice(keys...))
+	keyType := c.m.Type().Key()
+	for i := 0; i < k.Len(); i++ {
+		key := k.Index(i)
+		if !key.Type().AssignableTo(keyType) {
+			panic("tricks: counter.Inc: key doesn't match map's key type")
+		}
+		addCount(c.m, key, 1)
+	}
+	return c
+}
+
+// Add adds n to the count for key, and returns the same counter. n may be
+// negative; counts aren't kept from going below zero.
+func (c *Counter) Add(key interface{}, n int) *Counter {
+	k := valueOfType("counter.Add", "key", c.m.Type().Key(), key)
+	addCount(c.m, k, n)
+	return c
+}
+
+// Total returns the sum of all the counts.
+func (c *Counter) Total() int {
+	total := 0
+	for _, key := range c.m.MapKeys() {
+		total += int(c.m.MapIndex(key).Int())
+	}
+	return total
+}
+
+// MostCommon returns a slice of the n keys with the highest counts, in
+// descending order of count. Keys with equal counts are sorted as for Entries.
+// If n > number of keys, all of them are returned. It panics if n is negative.
+func (c *Counter) MostCommon(n int) TrickSlice {
+	keyType := c.m.Type().Key()
+	return TrickSlice(mostCommon("counter.MostCommon", reflect.SliceOf(keyType), sortedKeys(c.m), c.m, n))
+}
+
+// Plus returns a new Counter with the counts of this one and the other added
+// together. Only positive counts are kept.
+func (c *Counter) Plus(other *Counter) *Counter {
+	return c.combine("counter.Plus", other, 1)
+}
+
+// Minus returns a new Counter with the counts of the other subtracted from
+// those of this one. Only positive counts are kept.
+func (c *Counter) Minus(other *Counter) *Counter {
+	return c.combine("counter.Minus", other, -1)
+}
+
+func (c *Counter) combine(context string, other *Counter, sign int) *Counter {
+	if other.m.Type() != c.m.Type() {
+		panic("tricks: " + context + ": counter types don't match")
+	}
+	sum := reflect.Value(TrickMap(c.m).Copy())
+	for _, key := range other.m.MapKeys() {
+		addCount(sum, key, sign*int(other.m.MapIndex(key).Int()))
+	}
+	for _, key := range sum.MapKeys() {
+		if sum.MapIndex(key).Int() <= 0 {
+			sum.SetMapIndex(key, reflect.Value{})
+		}
+	}
+	return &Counter{sum}
+}
+
+// Map returns a copy of the counts as a map[K]int.
+func (c *Counter) Map() TrickMap {
+	return TrickMap(c.m).Copy()
+}
@@ -0,0 +1,85 @@
+package tricks
+
+import "reflect"
+
+// A DefaultMap is a map that makes a value for any key it's asked for but
+// doesn't hold yet, by calling a factory function, and stores it. It saves
+// checking whether a key is present before adding to its value, such as when
+// the values are maps or pointers to structs.
+type DefaultMap struct {
+	m       reflect.Value // map[K]V
+	factory reflect.Value // func() V or func(K) V
+}
+
+// NewDefaultMap makes a DefaultMap holding a copy of the entries of anyMap,
+// which may be a map or a TrickMap. factory is called to make the value for
+// any missing key, and is either a `func() V` or a `func(K) V`. Pass an empty
+// map to start from scratch.
+func NewDefaultMap(anyMap, factory interface{}) *DefaultMap {
+	v := mapValueOf("NewDefaultMap", anyMap)
+	f := reflect.ValueOf(factory)
+	if !f.IsValid() || !isValidFactoryFunc(f.Type(), v.Type()) {
+		panic("tricks: NewDefaultMap: invalid factory function type")
+	}
+	return &DefaultMap{reflect.Value(TrickMap(v).Copy()), f}
+}
+
+func isValidFactoryFunc(funcType, mapType reflect.Type) bool {
+	return funcType.Kind() == reflect.Func &&
+		(funcType.NumIn() == 0 || funcType.NumIn() == 1 && funcType.In(0) == mapType.Key()) &&
+		funcType.NumOut() == 1 && funcType.Out(0).AssignableTo(mapType.Elem())
+}
+
+// Len returns the number of entries in the map.
+func (dm *DefaultMap) Len() int {
+	return dm.m.Len()
+}
+
+// Get returns the value stored against key. If there isn't one, it makes one
+// with the factory function, stores it, and returns that.
+func (dm *DefaultMap) Get(key interface{}) interface{} {
+	k := valueOfType("defaultmap.Get", "key", dm.m.Type().Key(), key)
+	val := dm.m.MapIndex(k)
+	if !val.IsValid() {
+		var in []reflect.Value
+		if dm.factory.Type().NumIn() == 1 {
+			in = []reflect.Value{k}
+		}
+		val = dm.factory.Call(in)[0]
+		dm.m.SetMapIndex(k, val)
+	}
+	return val.Interface()
+}
+
+// Lookup returns the value stored against key, and whether it was found,
+// without making a new one.
+func (dm *DefaultMap) Lookup(key interface{}) (interface{}, bool) {
+	k := valueOfType("defaultmap.Lookup", "key", dm.m.Type().Key(), key)
+	return lookup(dm.m, k)
+}
+
+// Set stores value against key, and returns the same map.
+func (dm *DefaultMap) Set(key, value interface{}) *DefaultMap {
+	k := valueOfType("defaultmap.Set", "key", dm.m.Type().Key(), key)
+	v := valueOfType("defaultmap.Set", "value", dm.m.Type().Elem(), value)
+	dm.m.SetMapIndex(k, v)
+	return dm
+}
+
+// Delete removes the given keys from the map, if they're present, and returns
+// the same map. Delete accepts the same arguments as Slice()
+func (dm *DefaultMap) Delete(keys ...interface{}) *DefaultMap {
+	TrickMap(dm.m).Delete(keys...)
+	return dm
+}
+
+// HasKeys returns true if the map has all of the given keys, else false.
+// HasKeys accepts the same arguments as Slice()
+func (dm *DefaultMap) HasKeys(keys ...interface{}) bool {
+	return TrickMap(dm.m).HasKeys(keys...)
+}
+
+// Map returns a copy of the entries as a plain map.
+func (dm *DefaultMap) Map() TrickMap {
+	return TrickMap(dm.m).Copy()
+}
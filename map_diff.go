@@ -0,0 +1,126 @@
+package tricks
+
+import "reflect"
+
+// A MapDiff describes the differences between two maps: the entries that were
+// added, the entries that were removed, and the entries whose values changed.
+type MapDiff struct {
+	Added   TrickMap // entries only in the new map (map[K]V)
+	Removed TrickMap // entries only in the old map (map[K]V)
+	Changed TrickMap // keys in both maps whose values differ (map[K]Change)
+}
+
+// A Change holds the old and new values of a map entry that changed.
+type Change struct {
+	Old, New interface{}
+}
+
+var typeChange = reflect.TypeOf(Change{}) // Change
+
+// IsEmpty returns true if there are no differences, else false.
+func (d MapDiff) IsEmpty() bool {
+	return d.Added.IsEmpty() && d.Removed.IsEmpty() && d.Changed.IsEmpty()
+}
+
+func isValidEqualFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 2 && funcType.NumOut() == 1 &&
+		funcType.In(0) == mapType.Elem() &&
+		funcType.In(1) == mapType.Elem() &&
+		funcType.Out(0).Kind() == reflect.Bool
+}
+
+// valuesEqual compares two values with ==, if their types allow it, or with
+// reflect.DeepEqual if not.
+func valuesEqual(a, b reflect.Value) (equal bool) {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	if a.Type().Comparable() {
+		// Comparable types can still hold interfaces with uncomparable values,
+		// which panic on ==. Fall back to DeepEqual for those.
+		defer func() {
+			if recover() != nil {
+				equal = reflect.DeepEqual(a.Interface(), b.Interface())
+			}
+		}()
+		return a.Interface() == b.Interface()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// Diff compares this map (the old one) with other (the new one), which may be a
+// map or TrickMap of the same type. Values are compared with == where their
+// type allows it, and reflect.DeepEqual otherwise.
+func (tm TrickMap) Diff(other interface{}) MapDiff {
+	return diffMaps("map.Diff", tm, other, nil)
+}
+
+// DiffBy is like Diff, but compares values with eq, a `func(a, b V) bool` that
+// returns whether a and b are equal.
+func (tm TrickMap) DiffBy(other, eq interface{}) MapDiff {
+	return diffMaps("map.DiffBy", tm, other, eq)
+}
+
+func diffMaps(context string, tm TrickMap, other, eq interface{}) MapDiff {
+	v := reflect.Value(tm)
+	o := mapValueOf(context, other)
+	checkSameMapType(context, v, o)
+	equal := valuesEqual
+	if eq != nil {
+		f := reflect.ValueOf(eq)
+		if !f.IsValid() || !isValidEqualFunc(f.Type(), v.Type()) {
+			panic("tricks: " + context + ": invalid function type")
+		}
+		equal = func(a, b reflect.Value) bool {
+			return f.Call([]reflect.Value{a, b})[0].Bool()
+		}
+	}
+
+	added := reflect.MakeMap(v.Type())
+	removed := reflect.MakeMap(v.Type())
+	changed := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeChange))
+	for _, key := range v.MapKeys() {
+		oldVal := v.MapIndex(key)
+		newVal := o.MapIndex(key)
+		switch {
+		case !newVal.IsValid():
+			removed.SetMapIndex(key, oldVal)
+		case !equal(oldVal, newVal):
+			changed.SetMapIndex(key, reflect.ValueOf(Change{oldVal.Interface(), newVal.Interface()}))
+		}
+	}
+	for _, key := range o.MapKeys() {
+		if !v.MapIndex(key).IsValid() {
+			added.SetMapIndex(key, o.MapIndex(key))
+		}
+	}
+
+	return MapDiff{TrickMap(added), TrickMap(removed), TrickMap(changed)}
+}
+
+// Equal returns true if other (a map or TrickMap of the same type) has exactly
+// the same entries as this map, comparing values as Diff does.
+func (tm TrickMap) Equal(other interface{}) bool {
+	v := reflect.Value(tm)
+	o := mapValueOf("map.Equal", other)
+	checkSameMapType("map.Equal", v, o)
+	if v.Len() != o.Len() {
+		return false
+	}
+	for _, key := range v.MapKeys() {
+		val := o.MapIndex(key)
+		if !val.IsValid() || !valuesEqual(v.MapIndex(key), val) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package tricks
+
+import (
+	"reflect"
+	"sort"
+)
+
+// entryType returns the type of a map's entries, struct{ Key K; Value V }.
+func entryType(mapType reflect.Type) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: mapType.Key()},
+		{Name: "Value", Type: mapType.Elem()},
+	})
+}
+
+// Entries returns a slice of the map's entries, as key/value pairs of type
+// `struct{ Key K; Value V }`. The entries are sorted by key, if the keys are of
+// a type that Sort handles; otherwise there is no guarantee on their ordering.
+//
+// For example, a map[string]int gives a []struct{ Key string; Value int }.
+func (tm TrickMap) Entries() TrickSlice {
+	v := reflect.Value(tm)
+	typ := entryType(v.Type())
+
+	keys := sortedKeys(v)
+	out := reflect.MakeSlice(reflect.SliceOf(typ), len(keys), len(keys))
+	for i, key := range keys {
+		out.Index(i).Field(0).Set(key)
+		out.Index(i).Field(1).Set(v.MapIndex(key))
+	}
+
+	return TrickSlice(out)
+}
+
+// SortedEntriesBy returns the map's entries, as for Entries, sorted by some
+// comparison `func(a, b E) bool` that returns whether entry `a < b`. E is the
+// entry type `struct{ Key K; Value V }`, or any named struct type with the same
+// fields, in which case the entries are returned as that type.
+func (tm TrickMap) SortedEntriesBy(fn interface{}) TrickSlice {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || f.Kind() != reflect.Func || f.Type().NumIn() != 2 ||
+		!entryType(v.Type()).ConvertibleTo(f.Type().In(0)) ||
+		f.Type().In(0).Kind() != reflect.Struct {
+		panic("tricks: map.SortedEntriesBy: invalid function type")
+	}
+	typ := f.Type().In(0)
+	sliceType := reflect.SliceOf(typ)
+	if !isValidSortByFunc(f.Type(), sliceType) {
+		panic("tricks: map.SortedEntriesBy: invalid function type")
+	}
+
+	entries := reflect.Value(tm.Entries())
+	out := reflect.MakeSlice(sliceType, entries.Len(), entries.Len())
+	for i := 0; i < entries.Len(); i++ {
+		out.Index(i).Set(entries.Index(i).Convert(typ))
+	}
+	sort.Stable(&sortableBy{out, fn})
+	return TrickSlice(out)
+}
+
+// FromEntries builds a map from a slice (or TrickSlice) of key/value pairs,
+// such as the entries returned by Entries. It accepts the same pairs as
+// Associate does.
+func FromEntries(entries interface{}) TrickMap {
+	return Slice(entries).Associate()
+}
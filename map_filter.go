@@ -0,0 +1,240 @@
+package tricks
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Functions applied to maps take a key and a value, like `func(K, V) bool`.
+
+func isValidEntryFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 2 &&
+		funcType.In(0) == mapType.Key() &&
+		funcType.In(1) == mapType.Elem()
+}
+
+func isValidEntryBoolFunc(funcType, mapType reflect.Type) bool {
+	return isValidEntryFunc(funcType, mapType) &&
+		funcType.NumOut() == 1 && funcType.Out(0).Kind() == reflect.Bool
+}
+
+func isValidEachFunc(funcType, mapType reflect.Type) bool {
+	return isValidEntryFunc(funcType, mapType) && funcType.NumOut() == 0
+}
+
+func isValidMapReduceFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 3 && funcType.NumOut() == 1 &&
+		funcType.In(0) == funcType.Out(0) &&
+		funcType.In(1) == mapType.Key() &&
+		funcType.In(2) == mapType.Elem()
+}
+
+func isValidMapKeysFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 1 && funcType.NumOut() == 1 &&
+		funcType.In(0) == mapType.Key()
+}
+
+func isValidMapValuesFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 1 && funcType.NumOut() == 1 &&
+		funcType.In(0) == mapType.Elem()
+}
+
+// A resolve function picks the value to keep when two values meet at the same
+// key: `func(key K, a, b V) V`.
+func isValidResolveFunc(funcType, mapType reflect.Type) bool {
+	return funcType.NumIn() == 3 && funcType.NumOut() == 1 &&
+		funcType.In(0) == mapType.Key() &&
+		funcType.In(1) == mapType.Elem() &&
+		funcType.In(2) == mapType.Elem() &&
+		funcType.Out(0) == mapType.Elem()
+}
+
+// Any returns true if the given function returns true for any entry in the
+// map. Otherwise, it returns false.
+func (tm TrickMap) Any(fn interface{}) bool {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEntryBoolFunc(f.Type(), v.Type()) {
+		panic("tricks: map.Any: invalid function type")
+	}
+
+	for _, key := range v.MapKeys() {
+		if f.Call([]reflect.Value{key, v.MapIndex(key)})[0].Bool() {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns true if the given function returns true for every entry in the
+// map. Otherwise, it returns false.
+func (tm TrickMap) All(fn interface{}) bool {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEntryBoolFunc(f.Type(), v.Type()) {
+		panic("tricks: map.All: invalid function type")
+	}
+
+	for _, key := range v.MapKeys() {
+		if !f.Call([]reflect.Value{key, v.MapIndex(key)})[0].Bool() {
+			return false
+		}
+	}
+	return true
+}
+
+// None returns true if the given function returns false for every entry in the
+// map. Otherwise, it returns false.
+func (tm TrickMap) None(fn interface{}) bool {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEntryBoolFunc(f.Type(), v.Type()) {
+		panic("tricks: map.None: invalid function type")
+	}
+
+	for _, key := range v.MapKeys() {
+		if f.Call([]reflect.Value{key, v.MapIndex(key)})[0].Bool() {
+			return false
+		}
+	}
+	return true
+}
+
+// Each calls the given `func(K, V)` for every entry in the map. There is no
+// guarantee on the order of the calls.
+func (tm TrickMap) Each(fn interface{}) {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEachFunc(f.Type(), v.Type()) {
+		panic("tricks: map.Each: invalid function type")
+	}
+
+	for _, key := range v.MapKeys() {
+		f.Call([]reflect.Value{key, v.MapIndex(key)})
+	}
+}
+
+// FilterMap returns a new map, choosing only the entries for which the given
+// `func(K, V) bool` returns true.
+func (tm TrickMap) FilterMap(fn interface{}) TrickMap {
+	return filterMap("map.FilterMap", tm, fn, true)
+}
+
+// PickBy is the same as FilterMap; it returns a new map, choosing only the
+// entries for which the given `func(K, V) bool` returns true.
+func (tm TrickMap) PickBy(fn interface{}) TrickMap {
+	return filterMap("map.PickBy", tm, fn, true)
+}
+
+// OmitBy is the opposite of PickBy; it returns a new map, leaving out the
+// entries for which the given `func(K, V) bool` returns true.
+func (tm TrickMap) OmitBy(fn interface{}) TrickMap {
+	return filterMap("map.OmitBy", tm, fn, false)
+}
+
+func filterMap(context string, tm TrickMap, fn interface{}, keep bool) TrickMap {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEntryBoolFunc(f.Type(), v.Type()) {
+		panic("tricks: " + context + ": invalid function type")
+	}
+
+	out := reflect.MakeMap(v.Type())
+	for _, key := range v.MapKeys() {
+		val := v.MapIndex(key)
+		if f.Call([]reflect.Value{key, val})[0].Bool() == keep {
+			out.SetMapIndex(key, val)
+		}
+	}
+
+	return TrickMap(out)
+}
+
+// MapValues applies the given `func(V) X` to each value of the map and stores
+// the result against the same key in a new map (`map[K]X`).
+func (tm TrickMap) MapValues(fn interface{}) TrickMap {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapValuesFunc(f.Type(), v.Type()) {
+		panic("tricks: map.MapValues: invalid function type")
+	}
+	mapType := reflect.MapOf(v.Type().Key(), f.Type().Out(0))
+
+	out := reflect.MakeMap(mapType)
+	for _, key := range v.MapKeys() {
+		out.SetMapIndex(key, f.Call([]reflect.Value{v.MapIndex(key)})[0])
+	}
+
+	return TrickMap(out)
+}
+
+// MapKeys applies the given `func(K) X` to each key of the map and stores the
+// value against the result in a new map (`map[X]V`). If two keys map to the
+// same new key, MapKeys panics, since there's no telling which of the values
+// would be kept. Use MapKeysWith to choose.
+func (tm TrickMap) MapKeys(fn interface{}) TrickMap {
+	return mapKeys("map.MapKeys", tm, fn, nil)
+}
+
+// MapKeysWith is like MapKeys, but if two keys map to the same new key, the
+// value is chosen by calling resolve, a `func(key X, a, b V) V`.
+func (tm TrickMap) MapKeysWith(fn, resolve interface{}) TrickMap {
+	return mapKeys("map.MapKeysWith", tm, fn, resolve)
+}
+
+func mapKeys(context string, tm TrickMap, fn, resolve interface{}) TrickMap {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapKeysFunc(f.Type(), v.Type()) {
+		panic("tricks: " + context + ": invalid function type")
+	}
+	keyType := f.Type().Out(0)
+	if !keyType.Comparable() {
+		panic("tricks: " + context + ": key type is not comparable")
+	}
+	mapType := reflect.MapOf(keyType, v.Type().Elem())
+	r := reflect.ValueOf(resolve)
+	if resolve != nil && !isValidResolveFunc(r.Type(), mapType) {
+		panic("tricks: " + context + ": invalid resolve function type")
+	}
+
+	out := reflect.MakeMap(mapType)
+	for _, key := range v.MapKeys() {
+		newKey := f.Call([]reflect.Value{key})[0]
+		val := v.MapIndex(key)
+		if prev := out.MapIndex(newKey); prev.IsValid() {
+			if resolve == nil {
+				panic(fmt.Sprintf("tricks: %s: duplicate key %v", context, newKey.Interface()))
+			}
+			val = r.Call([]reflect.Value{newKey, prev, val})[0]
+		}
+		out.SetMapIndex(newKey, val)
+	}
+
+	return TrickMap(out)
+}
+
+// Reduce applies the given function to the entries of the map and reduces them
+// down to a single value. fn should be `func(X, K, V) X`, zero should be type
+// X. There is no guarantee on the order the entries are visited in.
+func (tm TrickMap) Reduce(zero, fn interface{}) interface{} {
+	v := reflect.Value(tm)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapReduceFunc(f.Type(), v.Type()) {
+		panic("tricks: map.Reduce: invalid function type")
+	}
+	outType := f.Type().Out(0)
+	z := reflect.ValueOf(zero)
+	if !z.IsValid() {
+		z = reflect.Zero(outType)
+	}
+	if z.Type() != outType {
+		panic("tricks: map.Reduce: invalid zero type")
+	}
+
+	for _, key := range v.MapKeys() {
+		z = f.Call([]reflect.Value{z, key, v.MapIndex(key)})[0]
+	}
+
+	return z.Interface()
+}
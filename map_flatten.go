@@ -0,0 +1,131 @@
+package tricks
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FlattenKeys returns a new map[string]interface{}, with the values of any
+// nested maps and slices lifted up to the top level, under keys made by
+// joining the path to them with sep. So `{"a": {"b": 1, "c": [2, 3]}}` becomes
+// `{"a.b": 1, "a.c.0": 2, "a.c.1": 3}` with a sep of ".". Keys are formatted
+// with fmt.Sprint, and empty maps and slices are kept as they are. If two paths
+// end up with the same key (like "a.b" and "a" → "b"), FlattenKeys returns a
+// *DuplicateKeyError.
+func (tm TrickMap) FlattenKeys(sep string) (TrickMap, error) {
+	if sep == "" {
+		panic("tricks: map.FlattenKeys: separator is empty")
+	}
+	v := reflect.Value(tm)
+	out := reflect.ValueOf(make(map[string]interface{}))
+	if v.IsValid() {
+		for _, key := range v.MapKeys() {
+			if err := flattenInto(out, fmt.Sprint(key.Interface()), sep, v.MapIndex(key)); err != nil {
+				return TrickMap{}, err
+			}
+		}
+	}
+	return TrickMap(out), nil
+}
+
+// flattenInto stores v in out under the key prefix, or if v is a non-empty map
+// or slice, stores its values under keys starting with prefix.
+func flattenInto(out reflect.Value, prefix, sep string, v reflect.Value) error {
+	v = unwrap(v)
+	switch {
+	case v.Kind() == reflect.Map && v.Len() > 0:
+		for _, key := range v.MapKeys() {
+			if err := flattenInto(out, prefix+sep+fmt.Sprint(key.Interface()), sep, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() > 0:
+		for i := 0; i < v.Len(); i++ {
+			if err := flattenInto(out, prefix+sep+strconv.Itoa(i), sep, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	key := reflect.ValueOf(prefix)
+	if out.MapIndex(key).IsValid() {
+		return &DuplicateKeyError{"map.FlattenKeys", prefix}
+	}
+	if !v.IsValid() {
+		v = reflect.Zero(typeInterface)
+	}
+	out.SetMapIndex(key, v)
+	return nil
+}
+
+// Unflatten is the opposite of FlattenKeys. It splits the string keys of the
+// map on sep and nests the values in map[string]interface{}s along those
+// paths. Any nested map whose keys are exactly "0", "1", … "n-1" becomes a
+// []interface{}. If a key is both a value and a path to other values (like
+// "a" and "a.b"), Unflatten returns a *DuplicateKeyError for it.
+func (tm TrickMap) Unflatten(sep string) (TrickMap, error) {
+	v := reflect.Value(tm)
+	if sep == "" {
+		panic("tricks: map.Unflatten: separator is empty")
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		panic("tricks: map.Unflatten: map keys are not strings")
+	}
+
+	root := make(flatNode)
+	for _, key := range sortedKeys(v) {
+		path := strings.Split(key.String(), sep)
+		node := root
+		for i, seg := range path[:len(path)-1] {
+			next, ok := node[seg]
+			if !ok {
+				next = make(flatNode)
+				node[seg] = next
+			}
+			if node, ok = next.(flatNode); !ok {
+				return TrickMap{}, &DuplicateKeyError{"map.Unflatten", strings.Join(path[:i+1], sep)}
+			}
+		}
+		last := path[len(path)-1]
+		if _, ok := node[last]; ok {
+			return TrickMap{}, &DuplicateKeyError{"map.Unflatten", key.String()}
+		}
+		node[last] = v.MapIndex(key).Interface()
+	}
+
+	return TrickMap(reflect.ValueOf(root.toMap())), nil
+}
+
+// A flatNode is a map made by Unflatten, as opposed to a map that was one of
+// the values being unflattened, which is left alone.
+type flatNode map[string]interface{}
+
+func (n flatNode) toMap() map[string]interface{} {
+	out := make(map[string]interface{}, len(n))
+	for k, val := range n {
+		if nested, ok := val.(flatNode); ok {
+			val = nested.toValue()
+		}
+		out[k] = val
+	}
+	return out
+}
+
+// toValue converts the node to a []interface{} if its keys are all indexes (0
+// to n-1), or else a map[string]interface{}.
+func (n flatNode) toValue() interface{} {
+	m := n.toMap()
+	out := make([]interface{}, len(m))
+	for k, val := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return m
+		}
+		out[i] = val
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package tricks
+
+import "reflect"
+
+// A MergeStrategy chooses between two values for the same key when merging
+// maps with MergeWith.
+type MergeStrategy int
+
+const (
+	LastWins  MergeStrategy = iota // keep the value from the map merged last
+	FirstWins                      // keep the value from the map merged first
+)
+
+// A SliceMerge chooses what DeepMerge does when two maps both hold a slice
+// under the same key.
+type SliceMerge int
+
+const (
+	ReplaceSlices SliceMerge = iota // keep the slice from the map merged last
+	ConcatSlices                    // append the later slice to the earlier one
+)
+
+// mapValueOf returns the map held by m, which may be a map or a TrickMap.
+func mapValueOf(context string, m interface{}) reflect.Value {
+	if tm, ok := m.(TrickMap); ok {
+		return reflect.Value(tm)
+	}
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		panic("tricks: " + context + ": input is not a map")
+	}
+	return v
+}
+
+func checkSameMapType(context string, v, other reflect.Value) {
+	if !other.Type().Key().AssignableTo(v.Type().Key()) ||
+		!other.Type().Elem().AssignableTo(v.Type().Elem()) {
+		panic("tricks: " + context + ": map types don't match")
+	}
+}
+
+// Merge returns a new map with the entries of this map, overlaid in turn with
+// the entries of each of the others. Where maps share a key, the value from
+// the last one wins. The others may be maps or TrickMaps, and must have the
+// same key and value types as this map.
+func (tm TrickMap) Merge(others ...interface{}) TrickMap {
+	return tm.merge("map.Merge", LastWins, others)
+}
+
+// MergeWith is like Merge, but chooses between values for the same key using
+// resolve, which is either a MergeStrategy (LastWins or FirstWins) or a
+// `func(key K, a, b V) V` that is given the earlier and later values.
+func (tm TrickMap) MergeWith(resolve interface{}, others ...interface{}) TrickMap {
+	return tm.merge("map.MergeWith", resolve, others)
+}
+
+func (tm TrickMap) merge(context string, resolve interface{}, others []interface{}) TrickMap {
+	v := reflect.Value(tm)
+	strategy, isStrategy := resolve.(MergeStrategy)
+	r := reflect.ValueOf(resolve)
+	if !isStrategy && (!r.IsValid() || r.Kind() != reflect.Func || !isValidResolveFunc(r.Type(), v.Type())) {
+		panic("tricks: " + context + ": invalid resolve function type")
+	}
+
+	out := reflect.Value(tm.Copy())
+	for _, other := range others {
+		o := mapValueOf(context, other)
+		checkSameMapType(context, v, o)
+		for _, key := range o.MapKeys() {
+			val := o.MapIndex(key)
+			if prev := out.MapIndex(key); prev.IsValid() {
+				switch {
+				case !isStrategy:
+					val = r.Call([]reflect.Value{key, prev, val})[0]
+				case strategy == FirstWins:
+					continue
+				}
+			}
+			out.SetMapIndex(key, val)
+		}
+	}
+
+	return TrickMap(out)
+}
+
+// DeepMerge is like Merge, but where two maps both hold a nested map under the
+// same key (such as the map[string]interface{} values of decoded JSON), those
+// are merged too, recursively. Where both hold a slice, slices chooses whether
+// the later one replaces or is appended to the earlier one. For anything else,
+// the last value wins. None of the maps given are changed.
+func (tm TrickMap) DeepMerge(slices SliceMerge, others ...interface{}) TrickMap {
+	v := reflect.Value(tm)
+	out := reflect.Value(tm.Copy())
+	for _, other := range others {
+		o := mapValueOf("map.DeepMerge", other)
+		checkSameMapType("map.DeepMerge", v, o)
+		deepMergeInto(out, o, slices)
+	}
+	return TrickMap(out)
+}
+
+// deepMergeInto merges the entries of src into dst, which it changes. Nested
+// maps in dst are copied before being merged into, so src is never changed.
+func deepMergeInto(dst, src reflect.Value, slices SliceMerge) {
+	for _, key := range src.MapKeys() {
+		val := src.MapIndex(key)
+		if prev := dst.MapIndex(key); prev.IsValid() {
+			if merged, ok := deepMergeValues(unwrap(prev), unwrap(val), slices); ok &&
+				merged.Type().AssignableTo(dst.Type().Elem()) {
+				val = merged
+			}
+		}
+		dst.SetMapIndex(key, val)
+	}
+}
+
+// deepMergeValues merges two maps, or two slices if they're being appended.
+// It returns false if a and b can't be merged.
+func deepMergeValues(a, b reflect.Value, slices SliceMerge) (reflect.Value, bool) {
+	switch {
+	case a.Kind() == reflect.Map && b.Kind() == reflect.Map:
+		if !b.Type().Key().AssignableTo(a.Type().Key()) ||
+			!b.Type().Elem().AssignableTo(a.Type().Elem()) {
+			return reflect.Value{}, false
+		}
+		out := reflect.Value(TrickMap(a).Copy())
+		deepMergeInto(out, b, slices)
+		return out, true
+
+	case a.Kind() == reflect.Slice && b.Kind() == reflect.Slice && slices == ConcatSlices:
+		typ := a.Type()
+		if b.Type() != typ {
+			typ = reflect.SliceOf(typeInterface)
+		}
+		out := reflect.MakeSlice(typ, 0, a.Len()+b.Len())
+		for _, s := range []reflect.Value{a, b} {
+			for i := 0; i < s.Len(); i++ {
+				out = reflect.Append(out, s.Index(i))
+			}
+		}
+		return out, true
+	}
+	return reflect.Value{}, false
+}
+
+// unwrap gets the concrete value out of an interface, or the map or slice out
+// of a TrickMap or TrickSlice.
+func unwrap(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.IsValid() {
+		switch v.Type() {
+		case typeTrickMap:
+			return reflect.Value(v.Interface().(TrickMap))
+		case typeTrickSlice:
+			return reflect.Value(v.Interface().(TrickSlice))
+		}
+	}
+	return v
+}
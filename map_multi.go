@@ -0,0 +1,134 @@
+package tricks
+
+import "reflect"
+
+// A MultiMap holds any number of values against each key, like the map[K][]V
+// that GroupBy returns, but with methods to add and remove values one at a
+// time. A key is only present while it has at least one value.
+type MultiMap struct {
+	m reflect.Value // map[K][]V
+}
+
+// NewMultiMap makes a MultiMap holding a copy of the entries of anyMap, which
+// must be a map of slices (map[K][]V), or a TrickMap of one, such as GroupBy
+// returns. Pass an empty map to start from scratch. Keys with empty slices are
+// left out.
+func NewMultiMap(anyMap interface{}) *MultiMap {
+	v := mapValueOf("NewMultiMap", anyMap)
+	if v.Type().Elem().Kind() != reflect.Slice {
+		panic("tricks: NewMultiMap: input is not a map of slices")
+	}
+	out := reflect.MakeMap(v.Type())
+	for _, key := range v.MapKeys() {
+		if group := v.MapIndex(key); group.Len() > 0 {
+			out.SetMapIndex(key, copySlice(group))
+		}
+	}
+	return &MultiMap{out}
+}
+
+// copySlice returns a copy of a slice, with no room to append to in place.
+func copySlice(v reflect.Value) reflect.Value {
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(out, v)
+	return out
+}
+
+// Len returns the number of keys in the map.
+func (mm *MultiMap) Len() int {
+	return mm.m.Len()
+}
+
+// Add appends the given values to those held against key, and returns the same
+// map.
+func (mm *MultiMap) Add(key interface{}, values ...interface{}) *MultiMap {
+	mapType := mm.m.Type()
+	k := valueOfType("multimap.Add", "key", mapType.Key(), key)
+	for _, value := range values {
+		v := valueOfType("multimap.Add", "value", mapType.Elem().Elem(), value)
+		appendGroup(mm.m, k, v)
+	}
+	return mm
+}
+
+// Remove removes the first occurrence of value held against key, and returns
+// true if there was one. If that leaves the key with no values, the key is
+// removed too.
+func (mm *MultiMap) Remove(key, value interface{}) bool {
+	mapType := mm.m.Type()
+	k := valueOfType("multimap.Remove", "key", mapType.Key(), key)
+	v := valueOfType("multimap.Remove", "value", mapType.Elem().Elem(), value)
+
+	group := mm.m.MapIndex(k)
+	if !group.IsValid() {
+		return false
+	}
+	for i := 0; i < group.Len(); i++ {
+		if !valuesEqual(group.Index(i), v) {
+			continue
+		}
+		if group.Len() == 1 {
+			mm.m.SetMapIndex(k, reflect.Value{})
+			return true
+		}
+		out := reflect.MakeSlice(group.Type(), 0, group.Len()-1)
+		out = reflect.AppendSlice(out, group.Slice(0, i))
+		out = reflect.AppendSlice(out, group.Slice(i+1, group.Len()))
+		mm.m.SetMapIndex(k, out)
+		return true
+	}
+	return false
+}
+
+// Delete removes the given keys, along with all their values, and returns the
+// same map. Delete accepts the same arguments as Slice()
+func (mm *MultiMap) Delete(keys ...interface{}) *MultiMap {
+	TrickMap(mm.m).Delete(keys...)
+	return mm
+}
+
+// GetAll returns a copy of the values held against key, which is empty if the
+// key isn't in the map.
+func (mm *MultiMap) GetAll(key interface{}) TrickSlice {
+	k := valueOfType("multimap.GetAll", "key", mm.m.Type().Key(), key)
+	group := mm.m.MapIndex(k)
+	if !group.IsValid() {
+		return TrickSlice(reflect.MakeSlice(mm.m.Type().Elem(), 0, 0))
+	}
+	return TrickSlice(copySlice(group))
+}
+
+// HasKeys returns true if the map has all of the given keys, else false.
+// HasKeys accepts the same arguments as Slice()
+func (mm *MultiMap) HasKeys(keys ...interface{}) bool {
+	return TrickMap(mm.m).HasKeys(keys...)
+}
+
+// Keys returns a slice of the map's keys, sorted as for Entries.
+func (mm *MultiMap) Keys() TrickSlice {
+	keys := sortedKeys(mm.m)
+	out := reflect.MakeSlice(reflect.SliceOf(mm.m.Type().Key()), len(keys), len(keys))
+	for i, key := range keys {
+		out.Index(i).Set(key)
+	}
+	return TrickSlice(out)
+}
+
+// KeysWithCount returns a map of each key to the number of values it holds
+// (`map[K]int`).
+func (mm *MultiMap) KeysWithCount() TrickMap {
+	out := reflect.MakeMap(reflect.MapOf(mm.m.Type().Key(), typeInt))
+	for _, key := range mm.m.MapKeys() {
+		out.SetMapIndex(key, reflect.ValueOf(mm.m.MapIndex(key).Len()))
+	}
+	return TrickMap(out)
+}
+
+// Map returns a copy of the entries as a map[K][]V.
+func (mm *MultiMap) Map() TrickMap {
+	out := reflect.MakeMap(mm.m.Type())
+	for _, key := range mm.m.MapKeys() {
+		out.SetMapIndex(key, copySlice(mm.m.MapIndex(key)))
+	}
+	return TrickMap(out)
+}
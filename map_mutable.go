@@ -0,0 +1,67 @@
+package tricks
+
+import "reflect"
+
+// Unlike a TrickSlice, a TrickMap shares its underlying map, so these methods
+// change it in place.
+
+// Get returns the value stored against key, and whether it was found. If the
+// key isn't in the map, this method returns a nil value.
+func (tm TrickMap) Get(key interface{}) (interface{}, bool) {
+	v := reflect.Value(tm)
+	k := valueOfType("map.Get", "key", v.Type().Key(), key)
+	return lookup(v, k)
+}
+
+// GetOr returns the value stored against key, or def if the key isn't in the
+// map.
+func (tm TrickMap) GetOr(key, def interface{}) interface{} {
+	v := reflect.Value(tm)
+	k := valueOfType("map.GetOr", "key", v.Type().Key(), key)
+	if val, ok := lookup(v, k); ok {
+		return val
+	}
+	return def
+}
+
+// Set stores value against key in the map, and returns the same map.
+func (tm TrickMap) Set(key, value interface{}) TrickMap {
+	v := reflect.Value(tm)
+	if v.IsNil() {
+		panic("tricks: map.Set: map is nil")
+	}
+	k := valueOfType("map.Set", "key", v.Type().Key(), key)
+	val := valueOfType("map.Set", "value", v.Type().Elem(), value)
+	v.SetMapIndex(k, val)
+	return tm
+}
+
+// Delete removes the given keys from the map, if they're present, and returns
+// the same map. Delete accepts the same arguments as Slice()
+func (tm TrickMap) Delete(keys ...interface{}) TrickMap {
+	v := reflect.Value(tm)
+	k := reflect.Value(Slice(keys...))
+
+	keyType := v.Type().Key()
+
+	for i := 0; i < k.Len(); i++ {
+		key := k.Index(i)
+		if !key.Type().AssignableTo(keyType) {
+			panic("tricks: map.Delete: key doesn't match map's key type")
+		}
+		v.SetMapIndex(key, reflect.Value{})
+	}
+	return tm
+}
+
+// Pop removes key from the map and returns the value that was stored against
+// it. If the key isn't in the map, this method returns a nil value.
+func (tm TrickMap) Pop(key interface{}) interface{} {
+	v := reflect.Value(tm)
+	k := valueOfType("map.Pop", "key", v.Type().Key(), key)
+	val, ok := lookup(v, k)
+	if ok {
+		v.SetMapIndex(k, reflect.Value{})
+	}
+	return val
+}
@@ -0,0 +1,258 @@
+package tricks
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// An OrderedMap is a map that remembers the order its keys were first set in.
+// Keys, Values, Entries and Each all follow that order, and so does the JSON it
+// marshals to. Setting a key that's already in the map keeps its place.
+type OrderedMap struct {
+	m    reflect.Value   // map[K]V
+	keys []reflect.Value // K, in insertion order
+}
+
+// NewOrderedMap makes an OrderedMap holding a copy of the entries of anyMap,
+// which may be a map or a TrickMap. Since a Go map has no order to keep, the
+// keys start off sorted, as for Entries. Pass an empty map to start from
+// scratch.
+func NewOrderedMap(anyMap interface{}) *OrderedMap {
+	v := mapValueOf("NewOrderedMap", anyMap)
+	om := &OrderedMap{m: reflect.MakeMap(v.Type())}
+	for _, key := range sortedKeys(v) {
+		om.set(key, v.MapIndex(key))
+	}
+	return om
+}
+
+// OrderedFromEntries builds an OrderedMap from a slice (or TrickSlice) of
+// key/value pairs, such as the entries returned by Entries, keeping the keys in
+// the order they appear. It accepts the same pairs as Associate does. If more
+// than one pair has the same key, the last value wins, in the first one's place.
+func OrderedFromEntries(entries interface{}) *OrderedMap {
+	v := reflect.Value(Slice(entries))
+	keyType, valType := pairTypes(v.Type().Elem())
+	if keyType == nil {
+		panic("tricks: OrderedFromEntries: elements are not key/value pairs")
+	}
+	if !keyType.Comparable() {
+		panic("tricks: OrderedFromEntries: key type is not comparable")
+	}
+
+	om := &OrderedMap{m: reflect.MakeMap(reflect.MapOf(keyType, valType))}
+	for i := 0; i < v.Len(); i++ {
+		key, val, ok := splitPair(v.Index(i))
+		if !ok {
+			panic("tricks: OrderedFromEntries: elements are not key/value pairs")
+		}
+		om.set(key, val)
+	}
+	return om
+}
+
+// GroupByOrdered is like GroupBy, but returns an OrderedMap, with the groups in
+// the order their keys were first seen.
+func (ts TrickSlice) GroupByOrdered(fn interface{}) *OrderedMap {
+	v := reflect.Value(ts)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapFunc(f.Type(), v.Type()) {
+		panic("tricks: slice.GroupByOrdered: invalid function type")
+	}
+	mapType := reflect.MapOf(f.Type().Out(0), v.Type())
+
+	om := &OrderedMap{m: reflect.MakeMap(mapType)}
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		key := f.Call([]reflect.Value{val})[0]
+		if !om.m.MapIndex(key).IsValid() {
+			om.keys = append(om.keys, key)
+		}
+		appendGroup(om.m, key, val)
+	}
+	return om
+}
+
+func (om *OrderedMap) set(key, val reflect.Value) {
+	if !om.m.MapIndex(key).IsValid() {
+		om.keys = append(om.keys, key)
+	}
+	om.m.SetMapIndex(key, val)
+}
+
+// Len returns the number of entries in the map.
+func (om *OrderedMap) Len() int {
+	return len(om.keys)
+}
+
+// IsEmpty returns true if the map has no entries, else false.
+func (om *OrderedMap) IsEmpty() bool {
+	return om.Len() == 0
+}
+
+// Get returns the value stored against key, and whether it was found.
+func (om *OrderedMap) Get(key interface{}) (interface{}, bool) {
+	k := valueOfType("orderedmap.Get", "key", om.m.Type().Key(), key)
+	return lookup(om.m, k)
+}
+
+// Set stores value against key, adding key to the end of the order if it's
+// new. It returns the same map.
+func (om *OrderedMap) Set(key, value interface{}) *OrderedMap {
+	k := valueOfType("orderedmap.Set", "key", om.m.Type().Key(), key)
+	v := valueOfType("orderedmap.Set", "value", om.m.Type().Elem(), value)
+	om.set(k, v)
+	return om
+}
+
+// Delete removes the given keys from the map, if they're present, and returns
+// the same map. Delete accepts the same arguments as Slice()
+func (om *OrderedMap) Delete(keys ...interface{}) *OrderedMap {
+	drop := reflect.Value(TrickMap(om.m).Only(keys...))
+	if drop.Len() == 0 {
+		return om
+	}
+	kept := om.keys[:0]
+	for _, key := range om.keys {
+		if drop.MapIndex(key).IsValid() {
+			om.m.SetMapIndex(key, reflect.Value{})
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	om.keys = kept
+	return om
+}
+
+// HasKeys returns true if the map has all of the given keys, else false.
+// HasKeys accepts the same arguments as Slice()
+func (om *OrderedMap) HasKeys(keys ...interface{}) bool {
+	return TrickMap(om.m).HasKeys(keys...)
+}
+
+// Keys returns a slice of the map's keys, in order.
+func (om *OrderedMap) Keys() TrickSlice {
+	out := reflect.MakeSlice(reflect.SliceOf(om.m.Type().Key()), len(om.keys), len(om.keys))
+	for i, key := range om.keys {
+		out.Index(i).Set(key)
+	}
+	return TrickSlice(out)
+}
+
+// Values returns a slice of the map's values, in the order of their keys.
+func (om *OrderedMap) Values() TrickSlice {
+	out := reflect.MakeSlice(reflect.SliceOf(om.m.Type().Elem()), len(om.keys), len(om.keys))
+	for i, key := range om.keys {
+		out.Index(i).Set(om.m.MapIndex(key))
+	}
+	return TrickSlice(out)
+}
+
+// Entries returns a slice of the map's entries, in order, as key/value pairs of
+// type `struct{ Key K; Value V }`.
+func (om *OrderedMap) Entries() TrickSlice {
+	typ := entryType(om.m.Type())
+	out := reflect.MakeSlice(reflect.SliceOf(typ), len(om.keys), len(om.keys))
+	for i, key := range om.keys {
+		out.Index(i).Field(0).Set(key)
+		out.Index(i).Field(1).Set(om.m.MapIndex(key))
+	}
+	return TrickSlice(out)
+}
+
+// Each calls the given `func(K, V)` for every entry in the map, in order.
+func (om *OrderedMap) Each(fn interface{}) {
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidEachFunc(f.Type(), om.m.Type()) {
+		panic("tricks: orderedmap.Each: invalid function type")
+	}
+	for _, key := range om.keys {
+		f.Call([]reflect.Value{key, om.m.MapIndex(key)})
+	}
+}
+
+// Only returns a new OrderedMap containing only the given keys, keeping them in
+// the same order as this map. Only accepts the same arguments as Slice()
+func (om *OrderedMap) Only(keys ...interface{}) *OrderedMap {
+	keep := reflect.Value(TrickMap(om.m).Only(keys...))
+	out := &OrderedMap{m: reflect.MakeMap(om.m.Type())}
+	for _, key := range om.keys {
+		if val := keep.MapIndex(key); val.IsValid() {
+			out.set(key, val)
+		}
+	}
+	return out
+}
+
+// Copy returns a new OrderedMap containing the same entries, in the same order.
+func (om *OrderedMap) Copy() *OrderedMap {
+	out := &OrderedMap{m: reflect.MakeMap(om.m.Type())}
+	for _, key := range om.keys {
+		out.set(key, om.m.MapIndex(key))
+	}
+	return out
+}
+
+// Map returns a copy of the entries as a plain (unordered) map.
+func (om *OrderedMap) Map() TrickMap {
+	return TrickMap(om.m).Copy()
+}
+
+// MarshalJSON encodes the map as a JSON object, with its keys in order. Keys
+// are encoded the same way encoding/json does for maps: strings as they are,
+// integers in decimal, or using MarshalText if they implement
+// encoding.TextMarshaler.
+func (om *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range om.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := jsonKey(key)
+		if err != nil {
+			return nil, err
+		}
+		k, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		val, err := json.Marshal(om.m.MapIndex(key).Interface())
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonKey returns the string a map key is encoded as in a JSON object.
+func jsonKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	switch {
+	case !key.IsValid():
+		return "", fmt.Errorf("tricks: can't use nil as a JSON object key")
+	case key.Kind() == reflect.String:
+		return key.String(), nil
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("tricks: can't use %s as a JSON object key", key.Type())
+}
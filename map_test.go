@@ -1,6 +1,8 @@
 package tricks
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,3 +120,650 @@ func TestMapHasKeys(t *testing.T) {
 	assert.False(t, Map(alphabet).HasKeys("f"))
 	assert.False(t, Map(alphabet).HasKeys("F", "G"))
 }
+
+func TestMapAnyAllNone(t *testing.T) {
+	ages := Map(map[string]int{"ann": 31, "bob": 42, "cat": 17})
+	adult := func(name string, age int) bool { return age >= 18 }
+	named := func(name string, age int) bool { return name == "bob" }
+
+	assert.True(t, ages.Any(adult))
+	assert.False(t, ages.All(adult))
+	assert.False(t, ages.None(adult))
+	assert.True(t, ages.Any(named))
+	assert.True(t, ages.None(func(string, int) bool { return false }))
+	assert.True(t, Map(map[string]int{}).All(adult))
+
+	assert.Panics(t, func() { ages.Any(func(age int) bool { return true }) })
+	assert.Panics(t, func() { ages.All(func(age int, name string) bool { return true }) })
+	assert.Panics(t, func() { ages.None(nil) })
+}
+
+func TestMapEach(t *testing.T) {
+	total := 0
+	Map(map[string]int{"a": 1, "b": 2, "c": 3}).Each(func(k string, v int) { total += v })
+	assert.Equal(t, 6, total)
+
+	assert.Panics(t, func() { Map(map[string]int{}).Each(func(k string, v int) bool { return true }) })
+}
+
+func TestMapFilterMap(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 42, "cat": 17}
+
+	adults := Map(ages).FilterMap(func(name string, age int) bool { return age >= 18 })
+	assert.Equal(t, map[string]int{"ann": 31, "bob": 42}, adults.Value())
+	assert.Equal(t, 3, len(ages))
+
+	none := Map(ages).FilterMap(func(name string, age int) bool { return false })
+	assert.Equal(t, map[string]int{}, none.Value())
+}
+
+func TestMapMapValues(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 42}
+
+	doubled := Map(ages).MapValues(func(age int) float64 { return float64(age) / 2 })
+	assert.Equal(t, map[string]float64{"ann": 15.5, "bob": 21}, doubled.Value())
+
+	assert.Panics(t, func() { Map(ages).MapValues(func(name string) string { return name }) })
+}
+
+func TestMapMapKeys(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 42}
+
+	upper := Map(ages).MapKeys(strings.ToUpper)
+	assert.Equal(t, map[string]int{"ANN": 31, "BOB": 42}, upper.Value())
+
+	lengths := Map(ages).MapKeysWith(
+		func(name string) int { return len(name) },
+		func(key, a, b int) int { return a + b })
+	assert.Equal(t, map[int]int{3: 73}, lengths.Value())
+
+	assert.PanicsWithValue(t, "tricks: map.MapKeys: duplicate key 3", func() {
+		Map(ages).MapKeys(func(name string) int { return len(name) })
+	})
+	assert.Panics(t, func() { Map(ages).MapKeys(func(name string) []string { return nil }) })
+	assert.Panics(t, func() {
+		Map(ages).MapKeysWith(strings.ToUpper, func(a, b int) int { return a })
+	})
+}
+
+func TestMapReduce(t *testing.T) {
+	ages := Map(map[string]int{"ann": 31, "bob": 42})
+
+	total := ages.Reduce(0, func(sum int, name string, age int) int { return sum + age })
+	assert.Equal(t, 73, total)
+
+	letters := ages.Reduce(0, func(n int, name string, age int) int { return n + len(name) })
+	assert.Equal(t, 6, letters)
+
+	assert.Panics(t, func() { ages.Reduce("0", func(sum int, name string, age int) int { return 0 }) })
+	assert.Panics(t, func() { ages.Reduce(0, func(sum int, age int) int { return 0 }) })
+}
+
+func TestMapMerge(t *testing.T) {
+	defaults := map[string]int{"port": 80, "workers": 4}
+	file := map[string]int{"port": 8080}
+	env := Map(map[string]int{"workers": 8, "debug": 1})
+
+	merged := Map(defaults).Merge(file, env)
+	assert.Equal(t, map[string]int{"port": 8080, "workers": 8, "debug": 1}, merged.Value())
+	assert.Equal(t, map[string]int{"port": 80, "workers": 4}, defaults)
+
+	assert.Equal(t, defaults, Map(defaults).Merge().Value())
+	assert.Panics(t, func() { Map(defaults).Merge(map[string]string{}) })
+	assert.Panics(t, func() { Map(defaults).Merge(42) })
+}
+
+func TestMapMergeWith(t *testing.T) {
+	a := map[string]int{"x": 1, "y": 2}
+	b := map[string]int{"y": 20, "z": 30}
+	c := map[string]int{"y": 200}
+
+	assert.Equal(t, map[string]int{"x": 1, "y": 200, "z": 30}, Map(a).MergeWith(LastWins, b, c).Value())
+	assert.Equal(t, map[string]int{"x": 1, "y": 2, "z": 30}, Map(a).MergeWith(FirstWins, b, c).Value())
+
+	sum := func(key string, a, b int) int { return a + b }
+	assert.Equal(t, map[string]int{"x": 1, "y": 222, "z": 30}, Map(a).MergeWith(sum, b, c).Value())
+
+	assert.Panics(t, func() { Map(a).MergeWith(func(a, b int) int { return a }, b) })
+	assert.Panics(t, func() { Map(a).MergeWith(nil, b) })
+}
+
+func TestMapDeepMerge(t *testing.T) {
+	defaults := map[string]interface{}{
+		"name": "app",
+		"db":   map[string]interface{}{"host": "localhost", "port": 5432},
+		"tags": []string{"a"},
+	}
+	overrides := map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db.example.com"},
+		"tags": []string{"b"},
+		"mode": "prod",
+	}
+
+	replaced := Map(defaults).DeepMerge(ReplaceSlices, overrides).Value()
+	assert.Equal(t, map[string]interface{}{
+		"name": "app",
+		"db":   map[string]interface{}{"host": "db.example.com", "port": 5432},
+		"tags": []string{"b"},
+		"mode": "prod",
+	}, replaced)
+
+	concatenated := Map(defaults).DeepMerge(ConcatSlices, overrides, map[string]interface{}{
+		"tags": []interface{}{1},
+		"db":   Map(map[string]interface{}{"port": 6543}),
+	}).Value()
+	assert.Equal(t, map[string]interface{}{
+		"name": "app",
+		"db":   map[string]interface{}{"host": "db.example.com", "port": 6543},
+		"tags": []interface{}{"a", "b", 1},
+		"mode": "prod",
+	}, concatenated)
+
+	// The inputs are left alone.
+	assert.Equal(t, map[string]interface{}{"host": "localhost", "port": 5432}, defaults["db"])
+	assert.Equal(t, []string{"a"}, defaults["tags"])
+
+	// A map replaces a non-map, and vice versa.
+	flipped := Map(defaults).DeepMerge(ReplaceSlices, map[string]interface{}{"db": "none"}).Value()
+	assert.Equal(t, "none", flipped.(map[string]interface{})["db"])
+}
+
+func TestMapOnlyStrict(t *testing.T) {
+	var alphabet = map[string]string{
+		"A": "Apple",
+		"B": "Ball",
+		"C": "Cat",
+	}
+
+	subset, err := Map(alphabet).OnlyStrict("A", "C")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "Apple", "C": "Cat"}, subset.Value())
+
+	_, err = Map(alphabet).OnlyStrict(Slice("A", "X", "B", "Y"))
+	assert.Equal(t, &MissingKeysError{"map.OnlyStrict", []interface{}{"X", "Y"}}, err)
+	assert.EqualError(t, err, "tricks: map.OnlyStrict: missing keys [X Y]")
+
+	assert.Panics(t, func() { Map(alphabet).OnlyStrict(1) })
+}
+
+func TestMapExcept(t *testing.T) {
+	user := map[string]string{
+		"name":     "ann",
+		"email":    "ann@example.com",
+		"password": "hunter2",
+		"salt":     "xyz",
+	}
+
+	public := Map(user).Except("password", "salt").Value().(map[string]string)
+	assert.Equal(t, map[string]string{"name": "ann", "email": "ann@example.com"}, public)
+	assert.Equal(t, 4, len(user))
+
+	assert.Equal(t, user, Map(user).Except().Value())
+	assert.Equal(t, user, Map(user).Except([]string{"nope"}).Value())
+	assert.Equal(t, 2, Map(user).Except(Slice("password", "salt")).Len())
+
+	assert.Panics(t, func() { Map(user).Except(nil) })
+	assert.Panics(t, func() { Map(user).Except(1) })
+}
+
+func TestMapPickByOmitBy(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 42, "cat": 17}
+	adult := func(name string, age int) bool { return age >= 18 }
+
+	assert.Equal(t, map[string]int{"ann": 31, "bob": 42}, Map(ages).PickBy(adult).Value())
+	assert.Equal(t, map[string]int{"cat": 17}, Map(ages).OmitBy(adult).Value())
+
+	assert.Panics(t, func() { Map(ages).OmitBy(func(age int) bool { return true }) })
+}
+
+func TestMapInvert(t *testing.T) {
+	levels := map[string]int{"debug": 0, "info": 1, "warn": 2}
+
+	names, err := Map(levels).Invert()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{0: "debug", 1: "info", 2: "warn"}, names.Value())
+
+	_, err = Map(map[string]int{"warn": 2, "warning": 2}).Invert()
+	assert.Equal(t, &DuplicateKeyError{"map.Invert", 2}, err)
+
+	assert.Panics(t, func() { Map(map[string][]int{}).Invert() })
+}
+
+func TestMapInvertGrouped(t *testing.T) {
+	levels := map[string]int{"debug": 0, "warning": 2, "info": 1, "warn": 2}
+
+	grouped := Map(levels).InvertGrouped().Value().(map[int][]string)
+	assert.Equal(t, map[int][]string{
+		0: []string{"debug"},
+		1: []string{"info"},
+		2: []string{"warn", "warning"},
+	}, grouped)
+}
+
+func TestBiMap(t *testing.T) {
+	source := map[string]int{"debug": 0, "info": 1}
+	levels, err := NewBiMap(source)
+	assert.NoError(t, err)
+
+	name, ok := levels.GetKey(1)
+	assert.True(t, ok)
+	assert.Equal(t, "info", name)
+	level, ok := levels.Get("debug")
+	assert.True(t, ok)
+	assert.Equal(t, 0, level)
+	_, ok = levels.Get("warn")
+	assert.False(t, ok)
+
+	levels.Set("warn", 2)
+	levels.Set("trace", 0) // takes 0 from debug
+	assert.Equal(t, map[string]int{"info": 1, "warn": 2, "trace": 0}, levels.Map().Value())
+	assert.Equal(t, map[int]string{0: "trace", 1: "info", 2: "warn"}, levels.Inverse().Value())
+
+	levels.Set("info", 3) // moves info from 1 to 3
+	assert.Equal(t, map[int]string{0: "trace", 2: "warn", 3: "info"}, levels.Inverse().Value())
+
+	levels.Delete("warn")
+	levels.DeleteValue(0)
+	levels.Delete("nope")
+	assert.Equal(t, 1, levels.Len())
+	assert.Equal(t, map[string]int{"info": 3}, levels.Map().Value())
+	assert.Equal(t, map[int]string{3: "info"}, levels.Inverse().Value())
+	assert.Equal(t, map[string]int{"debug": 0, "info": 1}, source)
+
+	assert.Panics(t, func() { levels.Set(1, 1) })
+	assert.Panics(t, func() { levels.Set("x", "y") })
+	assert.Panics(t, func() { levels.GetKey(nil) })
+
+	_, err = NewBiMap(map[string]int{"a": 1, "b": 1})
+	assert.Equal(t, &DuplicateKeyError{"NewBiMap", 1}, err)
+}
+
+func TestMapDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"name":  "ann",
+		"email": "ann@example.com",
+		"roles": []string{"user"},
+		"age":   31,
+	}
+	after := map[string]interface{}{
+		"name":  "ann",
+		"roles": []string{"user", "admin"},
+		"age":   32,
+		"phone": "555-1234",
+	}
+
+	diff := Map(before).Diff(after)
+	assert.Equal(t, map[string]interface{}{"phone": "555-1234"}, diff.Added.Value())
+	assert.Equal(t, map[string]interface{}{"email": "ann@example.com"}, diff.Removed.Value())
+	assert.Equal(t, map[string]Change{
+		"roles": {[]string{"user"}, []string{"user", "admin"}},
+		"age":   {31, 32},
+	}, diff.Changed.Value())
+	assert.False(t, diff.IsEmpty())
+
+	assert.True(t, Map(before).Diff(before).IsEmpty())
+	assert.True(t, Map(map[string]int{}).Diff(Map(map[string]int{})).IsEmpty())
+	assert.Panics(t, func() { Map(before).Diff(map[int]interface{}{}) })
+}
+
+func TestMapDiffBy(t *testing.T) {
+	before := map[string]float64{"a": 1.0, "b": 2.0}
+	after := map[string]float64{"a": 1.0001, "b": 3.0}
+
+	roughly := func(x, y float64) bool { return x-y < 0.01 && y-x < 0.01 }
+	diff := Map(before).DiffBy(after, roughly)
+	assert.Equal(t, map[string]Change{"b": {2.0, 3.0}}, diff.Changed.Value())
+
+	assert.Panics(t, func() { Map(before).DiffBy(after, func(x, y int) bool { return true }) })
+}
+
+func TestMapEqual(t *testing.T) {
+	a := map[string]interface{}{"x": 1, "y": []int{1, 2}, "z": map[string]int{"q": 1}}
+	b := map[string]interface{}{"x": 1, "y": []int{1, 2}, "z": map[string]int{"q": 1}}
+
+	assert.True(t, Map(a).Equal(b))
+	b["x"] = 1.0
+	assert.False(t, Map(a).Equal(b))
+	delete(b, "x")
+	assert.False(t, Map(a).Equal(b))
+
+	assert.True(t, Map(map[int]int{1: 1}).Equal(map[int]int{1: 1}))
+	assert.False(t, Map(map[int]int{1: 1}).Equal(map[int]int{2: 1}))
+
+	// Comparable types holding uncomparable values don't panic.
+	type holder struct{ v interface{} }
+	c := map[int]holder{1: {[]int{1}}}
+	assert.True(t, Map(c).Equal(map[int]holder{1: {[]int{1}}}))
+}
+
+func TestMapEntries(t *testing.T) {
+	scores := map[string]int{"bob": 42, "ann": 31, "cat": 17}
+
+	entries := Map(scores).Entries().Value().([]struct {
+		Key   string
+		Value int
+	})
+	assert.Equal(t, []struct {
+		Key   string
+		Value int
+	}{{"ann", 31}, {"bob", 42}, {"cat", 17}}, entries)
+
+	assert.Equal(t, 0, Map(map[int]bool{}).Entries().Len())
+}
+
+func TestMapFromEntries(t *testing.T) {
+	scores := map[string]int{"bob": 42, "ann": 31, "cat": 17}
+
+	assert.Equal(t, scores, FromEntries(Map(scores).Entries()).Value())
+	assert.Equal(t, scores, FromEntries(Map(scores).Entries().Value()).Value())
+
+	type score struct {
+		Name   string
+		Points int
+	}
+	assert.Equal(t, map[string]int{"ann": 31}, FromEntries([]score{{"ann", 31}}).Value())
+}
+
+func TestMapSortedEntriesBy(t *testing.T) {
+	scores := map[string]int{"bob": 42, "ann": 31, "cat": 17, "dan": 42}
+
+	type entry struct {
+		Key   string
+		Value int
+	}
+	byScore := func(a, b entry) bool { return a.Value > b.Value }
+	ranked := Map(scores).SortedEntriesBy(byScore).Pluck("Key").Value().([]string)
+	assert.Equal(t, []string{"bob", "dan", "ann", "cat"}, ranked)
+
+	assert.Panics(t, func() { Map(scores).SortedEntriesBy(func(a, b string) bool { return a < b }) })
+}
+
+func TestMapSortedEntriesByUnnamed(t *testing.T) {
+	scores := map[string]int{"bob": 42, "ann": 31}
+	byKeyDesc := func(a, b struct {
+		Key   string
+		Value int
+	}) bool {
+		return a.Key > b.Key
+	}
+	assert.Equal(t, []string{"bob", "ann"}, Map(scores).SortedEntriesBy(byKeyDesc).Pluck("Key").Value())
+}
+
+func TestMapGetSet(t *testing.T) {
+	ages := map[string]int{"ann": 31}
+
+	Map(ages).Set("bob", 42).Set("ann", 32)
+	assert.Equal(t, map[string]int{"ann": 32, "bob": 42}, ages)
+
+	age, ok := Map(ages).Get("bob")
+	assert.True(t, ok)
+	assert.Equal(t, 42, age)
+	age, ok = Map(ages).Get("cat")
+	assert.False(t, ok)
+	assert.Nil(t, age)
+
+	assert.Equal(t, 32, Map(ages).GetOr("ann", 0))
+	assert.Equal(t, -1, Map(ages).GetOr("cat", -1))
+
+	anything := Map(map[interface{}]interface{}{})
+	anything.Set(nil, nil).Set(1, "one")
+	value, ok := anything.Get(nil)
+	assert.True(t, ok)
+	assert.Nil(t, value)
+	assert.Equal(t, 2, anything.Len())
+
+	assert.Panics(t, func() { Map(ages).Set(1, 1) })
+	assert.Panics(t, func() { Map(ages).Set("cat", "17") })
+	assert.Panics(t, func() { Map(ages).Set(nil, 1) })
+	assert.Panics(t, func() { Map(ages).Get(1) })
+	assert.Panics(t, func() { Map(ages).GetOr(1, 0) })
+	assert.Panics(t, func() { Map(map[string]int(nil)).Set("a", 1) })
+}
+
+func TestMapDelete(t *testing.T) {
+	user := map[string]string{"name": "ann", "password": "hunter2", "salt": "xyz"}
+
+	Map(user).Delete("password", "salt", "nope")
+	assert.Equal(t, map[string]string{"name": "ann"}, user)
+	Map(user).Delete([]string{"name"})
+	assert.True(t, Map(user).IsEmpty())
+
+	assert.Panics(t, func() { Map(user).Delete(1) })
+}
+
+func TestMapPop(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 42}
+
+	assert.Equal(t, 31, Map(ages).Pop("ann"))
+	assert.Equal(t, map[string]int{"bob": 42}, ages)
+	assert.Nil(t, Map(ages).Pop("ann"))
+
+	assert.Panics(t, func() { Map(ages).Pop(1) })
+}
+
+func TestMapFlattenKeys(t *testing.T) {
+	config := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":  "localhost",
+			"ports": []int{5432, 5433},
+		},
+		"debug": true,
+		"tags":  []string{},
+		"extra": Map(map[string]int{"retries": 3}),
+		"none":  nil,
+	}
+
+	flat, err := Map(config).FlattenKeys("_")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db_host":       "localhost",
+		"db_ports_0":    5432,
+		"db_ports_1":    5433,
+		"debug":         true,
+		"tags":          []string{},
+		"extra_retries": 3,
+		"none":          nil,
+	}, flat.Value())
+
+	byID, err := Map(map[int]map[string]bool{1: {"ok": true}}).FlattenKeys(".")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"1.ok": true}, byID.Value())
+
+	// Only nested empty maps are kept; an empty map flattens to an empty map.
+	empty, err := Map(map[string]interface{}{}).FlattenKeys(".")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, empty.Value())
+	empty, err = TrickMap{}.FlattenKeys(".")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, empty.Value())
+
+	_, err = Map(map[string]interface{}{
+		"a.b": 1,
+		"a":   map[string]int{"b": 2},
+	}).FlattenKeys(".")
+	assert.Equal(t, &DuplicateKeyError{"map.FlattenKeys", "a.b"}, err)
+	assert.Panics(t, func() { Map(config).FlattenKeys("") })
+}
+
+func TestMapUnflatten(t *testing.T) {
+	flat := map[string]interface{}{
+		"db.host":    "localhost",
+		"db.ports.0": 5432,
+		"db.ports.1": 5433,
+		"db.opts.1":  "x",
+		"raw":        map[string]interface{}{"0": "kept"},
+		"debug":      true,
+	}
+
+	nested, err := Map(flat).Unflatten(".")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":  "localhost",
+			"ports": []interface{}{5432, 5433},
+			"opts":  map[string]interface{}{"1": "x"},
+		},
+		"raw":   map[string]interface{}{"0": "kept"},
+		"debug": true,
+	}, nested.Value())
+
+	// FlattenKeys undoes Unflatten.
+	nested, err = Map(flat).Except("raw").Unflatten(".")
+	assert.NoError(t, err)
+	reflat, err := nested.FlattenKeys(".")
+	assert.NoError(t, err)
+	assert.Equal(t, Map(flat).Except("raw").Value(), reflat.Value())
+
+	_, err = Map(map[string]int{"a": 1, "a.b": 2}).Unflatten(".")
+	assert.Equal(t, &DuplicateKeyError{"map.Unflatten", "a"}, err)
+
+	assert.Panics(t, func() { Map(map[int]int{}).Unflatten(".") })
+}
+
+func TestOrderedMap(t *testing.T) {
+	om := NewOrderedMap(map[string]int{"b": 2, "a": 1})
+	om.Set("d", 4).Set("c", 3).Set("a", 10)
+
+	assert.Equal(t, 4, om.Len())
+	assert.False(t, om.IsEmpty())
+	assert.Equal(t, []string{"a", "b", "d", "c"}, om.Keys().Value())
+	assert.Equal(t, []int{10, 2, 4, 3}, om.Values().Value())
+	assert.True(t, om.HasKeys("a", "c"))
+	assert.False(t, om.HasKeys("a", "z"))
+
+	val, ok := om.Get("d")
+	assert.True(t, ok)
+	assert.Equal(t, 4, val)
+	_, ok = om.Get("z")
+	assert.False(t, ok)
+
+	only := om.Only("c", "a", "z")
+	assert.Equal(t, []string{"a", "c"}, only.Keys().Value())
+
+	cp := om.Copy()
+	om.Delete("b", "z")
+	assert.Equal(t, []string{"a", "d", "c"}, om.Keys().Value())
+	assert.Equal(t, []string{"a", "b", "d", "c"}, cp.Keys().Value())
+	assert.Equal(t, map[string]int{"a": 10, "d": 4, "c": 3}, om.Map().Value())
+
+	var seen []string
+	om.Each(func(k string, v int) { seen = append(seen, k) })
+	assert.Equal(t, []string{"a", "d", "c"}, seen)
+
+	entries := om.Entries().Value().([]struct {
+		Key   string
+		Value int
+	})
+	assert.Equal(t, "d", entries[1].Key)
+	assert.Equal(t, 4, entries[1].Value)
+
+	assert.Panics(t, func() { om.Set(1, 1) })
+	assert.Panics(t, func() { om.Each(func(k string) {}) })
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	om := OrderedFromEntries([][2]string{{"zeta", "z"}, {"alpha", "a"}, {"zeta", "zz"}})
+	data, err := json.Marshal(om)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zeta":"zz","alpha":"a"}`, string(data))
+
+	byID := NewOrderedMap(map[int][]string{}).Set(3, []string{"c"}).Set(1, nil)
+	data, err = json.Marshal(byID)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"3":["c"],"1":null}`, string(data))
+
+	_, err = json.Marshal(NewOrderedMap(map[float64]bool{1.5: true}))
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { OrderedFromEntries([]int{1, 2}) })
+}
+
+func TestSliceGroupByOrdered(t *testing.T) {
+	words := []string{"pear", "fig", "apple", "kiwi", "plum", "date"}
+	groups := Slice(words).GroupByOrdered(func(s string) int { return len(s) })
+
+	assert.Equal(t, []int{4, 3, 5}, groups.Keys().Value())
+	assert.Equal(t, [][]string{
+		{"pear", "kiwi", "plum", "date"},
+		{"fig"},
+		{"apple"},
+	}, groups.Values().Value())
+}
+
+func TestMultiMap(t *testing.T) {
+	words := []string{"pear", "fig", "plum"}
+	mm := NewMultiMap(Slice(words).GroupBy(func(s string) int { return len(s) }))
+
+	mm.Add(3, "yam").Add(5, "apple", "mango")
+	assert.Equal(t, []string{"fig", "yam"}, mm.GetAll(3).Value())
+	assert.Equal(t, []string{}, mm.GetAll(6).Value())
+	assert.Equal(t, []int{3, 4, 5}, mm.Keys().Value())
+	assert.Equal(t, map[int]int{3: 2, 4: 2, 5: 2}, mm.KeysWithCount().Value())
+
+	assert.True(t, mm.Remove(4, "pear"))
+	assert.False(t, mm.Remove(4, "pear"))
+	assert.False(t, mm.Remove(7, "pear"))
+	assert.True(t, mm.Remove(4, "plum"))
+	assert.False(t, mm.HasKeys(4))
+	assert.Equal(t, 2, mm.Len())
+
+	m := mm.Map().Value().(map[int][]string)
+	m[3][0] = "changed"
+	assert.Equal(t, []string{"fig", "yam"}, mm.GetAll(3).Value())
+
+	mm.Delete(3)
+	assert.Equal(t, map[int][]string{5: {"apple", "mango"}}, mm.Map().Value())
+
+	assert.Panics(t, func() { mm.Add(5, 1) })
+	assert.Panics(t, func() { NewMultiMap(map[int]string{}) })
+}
+
+func TestCounter(t *testing.T) {
+	c := Slice([]string{"a", "b", "a", "c", "a", "b"}).Counter()
+	assert.Equal(t, 3, c.Get("a"))
+	assert.Equal(t, 0, c.Get("z"))
+
+	c.Inc("z").Inc("c", "c").Add("b", 5)
+	assert.Equal(t, map[string]int{"a": 3, "b": 7, "c": 3, "z": 1}, c.Map().Value())
+	assert.Equal(t, 14, c.Total())
+	assert.Equal(t, 4, c.Len())
+	assert.Equal(t, []string{"b", "a", "c"}, c.MostCommon(3).Value())
+	assert.PanicsWithValue(t, "tricks: counter.MostCommon: n is negative", func() { c.MostCommon(-1) })
+
+	other := NewCounter(map[string]int{"a": 1, "b": 10, "y": 2})
+	assert.Equal(t, map[string]int{"a": 4, "b": 17, "c": 3, "y": 2, "z": 1}, c.Plus(other).Map().Value())
+	assert.Equal(t, map[string]int{"a": 2, "c": 3, "z": 1}, c.Minus(other).Map().Value())
+	assert.Equal(t, 3, c.Get("a")) // unchanged
+
+	assert.Panics(t, func() { c.Plus(NewCounter(map[int]int{})) })
+	assert.Panics(t, func() { NewCounter(map[string]int64{}) })
+	assert.Panics(t, func() { c.Inc(1) })
+}
+
+func TestDefaultMap(t *testing.T) {
+	index := NewDefaultMap(map[string]map[string]bool{}, func() map[string]bool {
+		return make(map[string]bool)
+	})
+	index.Get("fruit").(map[string]bool)["apple"] = true
+	index.Get("fruit").(map[string]bool)["pear"] = true
+	index.Get("veg").(map[string]bool)["leek"] = true
+
+	assert.Equal(t, 2, index.Len())
+	assert.Equal(t, map[string]map[string]bool{
+		"fruit": {"apple": true, "pear": true},
+		"veg":   {"leek": true},
+	}, index.Map().Value())
+
+	_, ok := index.Lookup("nuts")
+	assert.False(t, ok)
+	assert.False(t, index.HasKeys("nuts"))
+
+	lengths := NewDefaultMap(map[string]int{"x": 0}, func(k string) int { return len(k) })
+	assert.Equal(t, 0, lengths.Get("x"))
+	assert.Equal(t, 5, lengths.Get("hello"))
+	lengths.Set("x", 9).Delete("hello")
+	assert.Equal(t, map[string]int{"x": 9}, lengths.Map().Value())
+
+	assert.Panics(t, func() { NewDefaultMap(map[string]int{}, func(k int) int { return k }) })
+	assert.Panics(t, func() { NewDefaultMap(map[string]int{}, nil) })
+}
@@ -0,0 +1,363 @@
+package tricks
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Paths like "users.0.address.city" reach into nested maps and slices, such as
+// JSON decoded into map[string]interface{} and []interface{}. Each segment of
+// the path, separated by dots, is either a map key or a slice index. Segments
+// are converted to the map's key type, so maps with integer keys work too.
+// Structs can be passed through by field name, and any TrickMaps, TrickSlices,
+// pointers or interfaces along the way are looked through.
+
+// A PathError describes where and why a path couldn't be followed.
+type PathError struct {
+	Op   string // the operation that failed, e.g. "map.GetPath"
+	Path string // the full path
+	At   string // the part of the path up to and including the failing segment
+	Err  error  // why it failed
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("tricks: %s: %q: at %q: %v", e.Op, e.Path, e.At, e.Err)
+}
+
+var (
+	errPathNoKey    = errors.New("no such key")
+	errPathNotIndex = errors.New("not a slice index")
+)
+
+// A pathWalker follows one path, keeping what it needs to report errors.
+type pathWalker struct {
+	op   string
+	path string
+	segs []string
+}
+
+func newPathWalker(op, path string) *pathWalker {
+	return &pathWalker{op, path, strings.Split(path, ".")}
+}
+
+func (w *pathWalker) fail(pos int, err error) error {
+	return &PathError{w.op, w.path, strings.Join(w.segs[:pos+1], "."), err}
+}
+
+// A wrapper records whether a value came wrapped in a TrickMap or TrickSlice,
+// so that it can be wrapped back up if we have to replace it.
+type wrapper int
+
+const (
+	notWrapped wrapper = iota
+	wrappedMap
+	wrappedSlice
+)
+
+// unwrapPath is like unwrap, but also follows pointers and tells us about any
+// TrickMap or TrickSlice it took the value out of.
+func unwrapPath(v reflect.Value) (reflect.Value, wrapper) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	w := notWrapped
+	if v.IsValid() {
+		switch v.Type() {
+		case typeTrickMap:
+			v, w = reflect.Value(v.Interface().(TrickMap)), wrappedMap
+		case typeTrickSlice:
+			v, w = reflect.Value(v.Interface().(TrickSlice)), wrappedSlice
+		}
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v, w
+}
+
+func (w wrapper) wrap(v reflect.Value) reflect.Value {
+	switch w {
+	case wrappedMap:
+		return reflect.ValueOf(TrickMap(v))
+	case wrappedSlice:
+		return reflect.ValueOf(TrickSlice(v))
+	}
+	return v
+}
+
+// pathKey converts a path segment to a key of the given type.
+func pathKey(seg string, keyType reflect.Type) (reflect.Value, error) {
+	key := reflect.ValueOf(seg)
+	if keyType.Kind() == reflect.Interface {
+		return key, nil
+	}
+	k := reflect.New(keyType).Elem()
+	if err := convertInto(k, key, ""); err != nil {
+		return reflect.Value{}, fmt.Errorf("key doesn't match map's key type %s", keyType)
+	}
+	return k, nil
+}
+
+// pathIndex parses a path segment as an index into a slice of length n.
+func pathIndex(seg string, n int) (int, error) {
+	i, err := strconv.Atoi(seg)
+	if err != nil {
+		return 0, errPathNotIndex
+	}
+	if i < 0 || i >= n {
+		return 0, fmt.Errorf("index %d out of range (length %d)", i, n)
+	}
+	return i, nil
+}
+
+// child returns the value under seg in c, which has been unwrapped.
+func (w *pathWalker) child(c reflect.Value, pos int) (reflect.Value, error) {
+	seg := w.segs[pos]
+	switch c.Kind() {
+	case reflect.Map:
+		key, err := pathKey(seg, c.Type().Key())
+		if err != nil {
+			return reflect.Value{}, w.fail(pos, err)
+		}
+		val := c.MapIndex(key)
+		if !val.IsValid() {
+			return reflect.Value{}, w.fail(pos, errPathNoKey)
+		}
+		return val, nil
+	case reflect.Slice, reflect.Array:
+		i, err := pathIndex(seg, c.Len())
+		if err != nil {
+			return reflect.Value{}, w.fail(pos, err)
+		}
+		return c.Index(i), nil
+	case reflect.Struct:
+		f := lookupField(c.Type(), seg)
+		if f.err != nil {
+			return reflect.Value{}, w.fail(pos, f.err)
+		}
+		val := fieldByIndex(c, f.index)
+		if !val.IsValid() {
+			return reflect.Value{}, w.fail(pos, errors.New("nil pointer"))
+		}
+		return val, nil
+	case reflect.Invalid:
+		return reflect.Value{}, w.fail(pos, errors.New("value is nil"))
+	}
+	return reflect.Value{}, w.fail(pos, fmt.Errorf("can't look inside a %s", c.Type()))
+}
+
+func (w *pathWalker) get(root reflect.Value) (interface{}, error) {
+	v := root
+	for pos := range w.segs {
+		c, _ := unwrapPath(v)
+		var err error
+		if v, err = w.child(c, pos); err != nil {
+			return nil, err
+		}
+	}
+	return v.Interface(), nil
+}
+
+// fitValue checks that val can be stored as a value of type typ.
+func fitValue(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if !val.IsValid() {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, fmt.Errorf("can't use nil as %s", typ)
+	}
+	if !val.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("can't use %s as %s", val.Type(), typ)
+	}
+	return val, nil
+}
+
+// set stores val at the path below cur, creating maps for any missing parts of
+// the path, and returns cur with the change made. cur itself may be replaced,
+// if it was missing, or a nil map that had to be made.
+func (w *pathWalker) set(cur reflect.Value, pos int, val reflect.Value) (reflect.Value, error) {
+	c, wrap := unwrapPath(cur)
+	replaced := false
+	if !c.IsValid() {
+		c, replaced = reflect.ValueOf(make(map[string]interface{})), true
+	}
+
+	seg := w.segs[pos]
+	setChild := func(old reflect.Value, typ reflect.Type) (reflect.Value, error) {
+		next := val
+		if pos < len(w.segs)-1 {
+			var err error
+			if next, err = w.set(old, pos+1, val); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		next, err := fitValue(next, typ)
+		if err != nil {
+			return reflect.Value{}, w.fail(pos, err)
+		}
+		return next, nil
+	}
+
+	switch c.Kind() {
+	case reflect.Map:
+		key, err := pathKey(seg, c.Type().Key())
+		if err != nil {
+			return reflect.Value{}, w.fail(pos, err)
+		}
+		if c.IsNil() {
+			if c.CanSet() {
+				c.Set(reflect.MakeMap(c.Type()))
+			} else {
+				c, replaced = reflect.MakeMap(c.Type()), true
+			}
+		}
+		next, err := setChild(c.MapIndex(key), c.Type().Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c.SetMapIndex(key, next)
+		if replaced {
+			return wrap.wrap(c), nil
+		}
+		return cur, nil
+
+	case reflect.Slice, reflect.Array, reflect.Struct:
+		el, err := w.child(c, pos)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !el.CanSet() {
+			return reflect.Value{}, w.fail(pos, fmt.Errorf("can't set inside a %s that isn't addressable", c.Type()))
+		}
+		next, err := setChild(el, el.Type())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		el.Set(next)
+		return cur, nil
+	}
+	return reflect.Value{}, w.fail(pos, fmt.Errorf("can't look inside a %s", c.Type()))
+}
+
+// delete removes whatever is at the path below cur, and returns cur with the
+// change made. Deleting from a slice makes a new slice, so cur may change.
+func (w *pathWalker) delete(cur reflect.Value, pos int) (reflect.Value, error) {
+	c, wrap := unwrapPath(cur)
+	if pos < len(w.segs)-1 {
+		el, err := w.child(c, pos)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		next, err := w.delete(el, pos+1)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		switch c.Kind() {
+		case reflect.Map:
+			key, _ := pathKey(w.segs[pos], c.Type().Key())
+			c.SetMapIndex(key, next)
+		default:
+			if !el.CanSet() {
+				return reflect.Value{}, w.fail(pos, fmt.Errorf("can't set inside a %s that isn't addressable", c.Type()))
+			}
+			el.Set(next)
+		}
+		return cur, nil
+	}
+
+	// Check the last segment exists before removing it.
+	if _, err := w.child(c, pos); err != nil {
+		return reflect.Value{}, err
+	}
+	switch c.Kind() {
+	case reflect.Map:
+		key, _ := pathKey(w.segs[pos], c.Type().Key())
+		c.SetMapIndex(key, reflect.Value{})
+		return cur, nil
+	case reflect.Slice:
+		i, _ := pathIndex(w.segs[pos], c.Len())
+		out := reflect.MakeSlice(c.Type(), 0, c.Len()-1)
+		out = reflect.AppendSlice(out, c.Slice(0, i))
+		out = reflect.AppendSlice(out, c.Slice(i+1, c.Len()))
+		if c.CanSet() {
+			c.Set(out)
+			return cur, nil
+		}
+		return wrap.wrap(out), nil
+	}
+	return reflect.Value{}, w.fail(pos, fmt.Errorf("can't delete from a %s", c.Type()))
+}
+
+// GetPath returns the value found by following the path (like
+// "users.0.address.city") through nested maps and slices. If the path can't be
+// followed, it returns a *PathError saying where and why.
+func (tm TrickMap) GetPath(path string) (interface{}, error) {
+	return newPathWalker("map.GetPath", path).get(reflect.Value(tm))
+}
+
+// HasPath returns true if the path can be followed to a value, else false.
+func (tm TrickMap) HasPath(path string) bool {
+	_, err := newPathWalker("map.HasPath", path).get(reflect.Value(tm))
+	return err == nil
+}
+
+// SetPath stores value at the end of the path, creating any maps missing along
+// the way as map[string]interface{}. Slice indexes must already exist. If the
+// path can't be followed, or the value doesn't fit where it's going, it returns
+// a *PathError.
+func (tm TrickMap) SetPath(path string, value interface{}) error {
+	v := reflect.Value(tm)
+	if v.IsNil() {
+		panic("tricks: map.SetPath: map is nil")
+	}
+	_, err := newPathWalker("map.SetPath", path).set(v, 0, reflect.ValueOf(value))
+	return err
+}
+
+// DeletePath removes the value at the end of the path, either a map entry or a
+// slice element (by making a new slice without it). If there's nothing there,
+// it returns a *PathError.
+func (tm TrickMap) DeletePath(path string) error {
+	_, err := newPathWalker("map.DeletePath", path).delete(reflect.Value(tm), 0)
+	return err
+}
+
+// GetPath returns the value found by following the path (like
+// "0.address.city") through nested slices and maps. If the path can't be
+// followed, it returns a *PathError saying where and why.
+func (ts TrickSlice) GetPath(path string) (interface{}, error) {
+	return newPathWalker("slice.GetPath", path).get(reflect.Value(ts))
+}
+
+// HasPath returns true if the path can be followed to a value, else false.
+func (ts TrickSlice) HasPath(path string) bool {
+	_, err := newPathWalker("slice.HasPath", path).get(reflect.Value(ts))
+	return err == nil
+}
+
+// SetPath stores value at the end of the path, creating any maps missing along
+// the way as map[string]interface{}. Slice indexes must already exist. If the
+// path can't be followed, or the value doesn't fit where it's going, it returns
+// a *PathError.
+func (ts *TrickSlice) SetPath(path string, value interface{}) error {
+	out, err := newPathWalker("slice.SetPath", path).set(reflect.Value(*ts), 0, reflect.ValueOf(value))
+	if err == nil {
+		*ts = TrickSlice(out)
+	}
+	return err
+}
+
+// DeletePath removes the value at the end of the path, either a map entry or a
+// slice element (by making a new slice without it). If there's nothing there,
+// it returns a *PathError.
+func (ts *TrickSlice) DeletePath(path string) error {
+	out, err := newPathWalker("slice.DeletePath", path).delete(reflect.Value(*ts), 0)
+	if err == nil {
+		*ts = TrickSlice(out)
+	}
+	return err
+}
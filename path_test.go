@@ -0,0 +1,167 @@
+package tricks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDoc() map[string]interface{} {
+	var doc map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"users": [
+			{"name": "ann", "address": {"city": "Paris"}, "tags": ["a", "b", "c"]},
+			{"name": "bob", "address": null}
+		],
+		"count": 2
+	}`), &doc)
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func TestMapGetPath(t *testing.T) {
+	doc := Map(testDoc())
+
+	city, err := doc.GetPath("users.0.address.city")
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris", city)
+
+	tag, err := doc.GetPath("users.0.tags.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", tag)
+
+	count, err := doc.GetPath("count")
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, count)
+
+	assert.True(t, doc.HasPath("users.1.name"))
+	assert.True(t, doc.HasPath("users.1.address"))
+	assert.False(t, doc.HasPath("users.1.address.city"))
+	assert.False(t, doc.HasPath("users.2"))
+	assert.False(t, doc.HasPath("nope"))
+
+	_, err = doc.GetPath("users.5.name")
+	assert.EqualError(t, err, `tricks: map.GetPath: "users.5.name": at "users.5": index 5 out of range (length 2)`)
+	pe, ok := err.(*PathError)
+	assert.True(t, ok)
+	assert.Equal(t, "users.5", pe.At)
+
+	_, err = doc.GetPath("users.x")
+	assert.EqualError(t, err, `tricks: map.GetPath: "users.x": at "users.x": not a slice index`)
+	_, err = doc.GetPath("users.0.nickname")
+	assert.EqualError(t, err, `tricks: map.GetPath: "users.0.nickname": at "users.0.nickname": no such key`)
+	_, err = doc.GetPath("users.1.address.city")
+	assert.EqualError(t, err, `tricks: map.GetPath: "users.1.address.city": at "users.1.address.city": value is nil`)
+	_, err = doc.GetPath("count.value")
+	assert.EqualError(t, err, `tricks: map.GetPath: "count.value": at "count.value": can't look inside a float64`)
+}
+
+func TestPathTypedContainers(t *testing.T) {
+	byID := Map(map[int][]string{1: {"x", "y"}})
+	y, err := byID.GetPath("1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "y", y)
+	_, err = byID.GetPath("one")
+	assert.EqualError(t, err, `tricks: map.GetPath: "one": at "one": key doesn't match map's key type int`)
+
+	assert.NoError(t, byID.SetPath("1.0", "z"))
+	assert.Equal(t, map[int][]string{1: {"z", "y"}}, byID.Value())
+	assert.NoError(t, byID.DeletePath("1.1"))
+	assert.Equal(t, map[int][]string{1: {"z"}}, byID.Value())
+
+	err = byID.SetPath("1.0", 5)
+	assert.EqualError(t, err, `tricks: map.SetPath: "1.0": at "1.0": can't use int as string`)
+	err = byID.SetPath("2.0", "a")
+	assert.EqualError(t, err, `tricks: map.SetPath: "2.0": at "2": can't use map[string]interface {} as []string`)
+
+	// Structs are passed through by field name, and can be set through pointers.
+	users := Slice([]*testAccount{{Name: "ann", Address: &testAddress{City: "Paris"}}})
+	city, err := users.GetPath("0.Address.City")
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris", city)
+	assert.NoError(t, users.SetPath("0.Address.City", "Rome"))
+	assert.Equal(t, "Rome", users.Value().([]*testAccount)[0].Address.City)
+
+	// Nested TrickMaps and TrickSlices are looked through, and stay wrapped.
+	nested := Map(map[string]interface{}{"list": Slice([]int{1, 2, 3})})
+	two, err := nested.GetPath("list.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, two)
+	assert.NoError(t, nested.DeletePath("list.0"))
+	list := nested.Value().(map[string]interface{})["list"].(TrickSlice)
+	assert.Equal(t, []int{2, 3}, list.Value())
+}
+
+func TestMapSetPath(t *testing.T) {
+	doc := Map(testDoc())
+
+	assert.NoError(t, doc.SetPath("users.1.address.city", "Oslo"))
+	city, _ := doc.GetPath("users.1.address.city")
+	assert.Equal(t, "Oslo", city)
+
+	assert.NoError(t, doc.SetPath("meta.source.name", "test"))
+	assert.Equal(t, map[string]interface{}{
+		"source": map[string]interface{}{"name": "test"},
+	}, doc.Value().(map[string]interface{})["meta"])
+
+	assert.NoError(t, doc.SetPath("users.0.tags.1", nil))
+	tags, _ := doc.GetPath("users.0.tags")
+	assert.Equal(t, []interface{}{"a", nil, "c"}, tags)
+
+	err := doc.SetPath("users.2.name", "cat")
+	assert.EqualError(t, err, `tricks: map.SetPath: "users.2.name": at "users.2": index 2 out of range (length 2)`)
+	err = doc.SetPath("count.value", 1)
+	assert.EqualError(t, err, `tricks: map.SetPath: "count.value": at "count.value": can't look inside a float64`)
+
+	assert.Panics(t, func() {
+		var m map[string]int
+		Map(m).SetPath("a", 1)
+	})
+}
+
+func TestMapDeletePath(t *testing.T) {
+	doc := Map(testDoc())
+
+	assert.NoError(t, doc.DeletePath("users.0.address.city"))
+	addr, _ := doc.GetPath("users.0.address")
+	assert.Equal(t, map[string]interface{}{}, addr)
+
+	assert.NoError(t, doc.DeletePath("users.0.tags.1"))
+	tags, _ := doc.GetPath("users.0.tags")
+	assert.Equal(t, []interface{}{"a", "c"}, tags)
+
+	assert.NoError(t, doc.DeletePath("users.0"))
+	name, _ := doc.GetPath("users.0.name")
+	assert.Equal(t, "bob", name)
+
+	err := doc.DeletePath("users.0.nickname")
+	assert.EqualError(t, err, `tricks: map.DeletePath: "users.0.nickname": at "users.0.nickname": no such key`)
+	err = doc.DeletePath("users.0.name.first")
+	assert.EqualError(t, err, `tricks: map.DeletePath: "users.0.name.first": at "users.0.name.first": can't look inside a string`)
+}
+
+func TestSlicePath(t *testing.T) {
+	rows := Slice([]interface{}{
+		map[string]interface{}{"id": 1},
+		nil,
+	})
+
+	assert.NoError(t, rows.SetPath("1.id", 2))
+	id, err := rows.GetPath("1.id")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.True(t, rows.HasPath("0.id"))
+
+	assert.NoError(t, rows.DeletePath("0"))
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": 2}}, rows.Value())
+
+	err = rows.DeletePath("3")
+	assert.EqualError(t, err, `tricks: slice.DeletePath: "3": at "3": index 3 out of range (length 1)`)
+
+	arr := Slice([2]int{1, 2})
+	assert.NoError(t, arr.DeletePath("0"))
+	assert.Equal(t, []int{2}, arr.Value())
+}
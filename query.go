@@ -0,0 +1,540 @@
+package tricks
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Queries chain the slice and map operations together from a string, for
+// working on data whose shape is only known at run time, such as decoded JSON.
+// A query is a pipeline of stages, separated by pipes, each of which takes the
+// output of the one before:
+//
+//	filter(.status == "ok") | group_by(.region) | only("eu", "us") | values | flatten | sort_by(.latency) | last(5)
+//
+// Stage arguments are expressions, worked out for each element. Paths like
+// .latency or .user.tags.0 reach into the element (see GetPath), and a lone
+// dot is the element itself. A path that leads nowhere gives null. Expressions
+// can use numbers, "strings", true, false and null, comparisons (== != < <= >
+// >=), arithmetic (+ - * /), and the logical operators && || and !.
+
+// A QuerySyntaxError describes a query that couldn't be parsed.
+type QuerySyntaxError struct {
+	Query string
+	Pos   int // the byte offset in Query where the problem was found
+	Msg   string
+	op    string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("tricks: %s: syntax error at column %d: %s", queryOpName(e.op), queryColumn(e.Query, e.Pos), e.Msg)
+}
+
+// A QueryError describes a stage of a query that couldn't be run on the data
+// it was given, or an expression made by Expr that couldn't be worked out.
+type QueryError struct {
+	Query string
+	Pos   int    // the byte offset in Query of the stage or expression that failed
+	Stage string // the name of the stage that failed, if it's from a query
+	Msg   string
+	op    string
+}
+
+func (e *QueryError) Error() string {
+	stage := e.Stage
+	if stage == "" {
+		stage = "error"
+	}
+	return fmt.Sprintf("tricks: %s: %s at column %d: %s", queryOpName(e.op), stage, queryColumn(e.Query, e.Pos), e.Msg)
+}
+
+// queryOpName names what an error came from in its message: a query, unless
+// it says otherwise.
+func queryOpName(op string) string {
+	if op == "" {
+		return "query"
+	}
+	return op
+}
+
+// queryColumn turns a byte offset into a column number, counting from 1.
+func queryColumn(query string, pos int) int {
+	if pos > len(query) {
+		pos = len(query)
+	}
+	return utf8.RuneCountInString(query[:pos]) + 1
+}
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // a name, like filter or true
+	tokField            // a path segment straight after a dot, like status or 0
+	tokNumber           // a number, held in val as a float64
+	tokString           // a quoted string, held in val
+	tokOp               // an operator or punctuation, like | or ==
+)
+
+type token struct {
+	kind tokenKind
+	text string // the token as it appears in the source
+	pos  int
+	val  interface{}
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+var queryOps2 = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+const queryOps1 = "|(),.<>!-+*/"
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isFieldChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// lexQuery splits a query into tokens, ending with a tokEOF.
+func lexQuery(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for {
+		for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+			i++
+		}
+		if i == len(src) {
+			return append(toks, token{kind: tokEOF, pos: i}), nil
+		}
+
+		start, c := i, src[i]
+		afterDot := false
+		if n := len(toks); n > 0 {
+			last := toks[n-1]
+			afterDot = last.kind == tokOp && last.text == "." && last.pos+1 == start
+		}
+
+		switch {
+		case afterDot && isFieldChar(c):
+			// Path segments may start with a digit, like .items.0
+			for i < len(src) && isFieldChar(src[i]) {
+				i++
+			}
+			toks = append(toks, token{tokField, src[start:i], start, src[start:i]})
+
+		case isIdentStart(c):
+			for i < len(src) && isFieldChar(src[i]) {
+				i++
+			}
+			toks = append(toks, token{tokIdent, src[start:i], start, nil})
+
+		case isDigit(c):
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i+1 < len(src) && src[i] == '.' && isDigit(src[i+1]) {
+				for i++; i < len(src) && isDigit(src[i]); i++ {
+				}
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && isDigit(src[j]) {
+					for i = j; i < len(src) && isDigit(src[i]); i++ {
+					}
+				}
+			}
+			f, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, &QuerySyntaxError{Query: src, Pos: start, Msg: fmt.Sprintf("bad number %q", src[start:i])}
+			}
+			toks = append(toks, token{tokNumber, src[start:i], start, f})
+
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src[start:])
+			if err != nil {
+				return nil, &QuerySyntaxError{Query: src, Pos: start + n, Msg: err.Error()}
+			}
+			i += n
+			toks = append(toks, token{tokString, src[start:i], start, s})
+
+		default:
+			op := ""
+			for _, o := range queryOps2 {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" && strings.IndexByte(queryOps1, c) >= 0 {
+				op = src[i : i+1]
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				return nil, &QuerySyntaxError{Query: src, Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			i += len(op)
+			toks = append(toks, token{tokOp, op, start, nil})
+		}
+	}
+}
+
+// lexString reads a quoted string from the start of s, returning its value and
+// how many bytes it took up. On error, the count is where the problem is.
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var buf []byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return string(buf), i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				buf = append(buf, '\n')
+			case 't':
+				buf = append(buf, '\t')
+			case '\\', '"', '\'':
+				buf = append(buf, s[i])
+			default:
+				return "", i - 1, fmt.Errorf("unknown escape \\%c in string", s[i])
+			}
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return "", 0, fmt.Errorf("string is missing its closing %c", quote)
+}
+
+// A queryExpr works out the value of an expression for the element x.
+type queryExpr func(x interface{}) (interface{}, error)
+
+// A queryParser turns tokens into stages and expressions. If expr is set, the
+// expressions are for Expr: names in them are fields of the element, so that
+// `age` means the same as `.age`, and struct fields are matched the way Decode
+// matches them.
+type queryParser struct {
+	src  string
+	toks []token
+	i    int
+	expr bool
+}
+
+func (p *queryParser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *queryParser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it's the operator op.
+func (p *queryParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorf(pos int, format string, args ...interface{}) error {
+	return &QuerySyntaxError{Query: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// runError makes the error for an expression that couldn't be worked out. The
+// stage is filled in by whatever runs the expression.
+func (p *queryParser) runError(pos int, format string, args ...interface{}) error {
+	return &QueryError{Query: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseExpr parses an expression, lowest precedence first:
+//
+//	||
+//	&&
+//	== != < <= > >=
+//	+ -
+//	* /
+//	! - (unary)
+func (p *queryParser) parseExpr() (queryExpr, error) {
+	return p.parseBinary(0)
+}
+
+var queryPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+func (p *queryParser) parseBinary(level int) (queryExpr, error) {
+	if level == len(queryPrecedence) {
+		return p.parseUnary()
+	}
+	lhs, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !containsString(queryPrecedence[level], t.text) {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		lhs = p.binary(t, lhs, rhs)
+		if level == 2 {
+			// Comparisons don't chain: a < b < c is a mistake.
+			if t := p.peek(); t.kind == tokOp && containsString(queryPrecedence[level], t.text) {
+				return nil, p.errorf(t.pos, "comparisons can't be chained; use && between them")
+			}
+		}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "!" {
+			return func(x interface{}) (interface{}, error) {
+				v, err := operand(x)
+				if err != nil {
+					return nil, err
+				}
+				return !truthy(v), nil
+			}, nil
+		}
+		return func(x interface{}) (interface{}, error) {
+			v, err := operand(x)
+			if err != nil {
+				return nil, err
+			}
+			n := reflect.ValueOf(v)
+			if !isNumber(n) {
+				return nil, p.runError(t.pos, "can't negate %s", queryKind(v))
+			}
+			return -toFloat(n), nil
+		}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString:
+		return queryConst(t.val), nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return queryConst(true), nil
+		case "false":
+			return queryConst(false), nil
+		case "null":
+			return queryConst(nil), nil
+		}
+		if p.expr {
+			return p.parsePath([]string{t.text}, t.pos)
+		}
+		return nil, p.errorf(t.pos, "unknown name %q (for a field, use .%s)", t.text, t.text)
+	case tokOp:
+		switch t.text {
+		case "(":
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, p.errorf(p.peek().pos, "expected \")\", found %s", p.peek())
+			}
+			return e, nil
+		case ".":
+			p.i-- // let parsePath read the dot
+			return p.parsePath(nil, t.pos)
+		}
+	}
+	return nil, p.errorf(t.pos, "expected an expression, found %s", t)
+}
+
+// parsePath parses the segments of a path starting at pos, each after a dot,
+// adding them to segs. A lone dot (with no segments at all) is the element
+// itself.
+func (p *queryParser) parsePath(segs []string, pos int) (queryExpr, error) {
+	for p.accept(".") {
+		t := p.peek()
+		switch t.kind {
+		case tokField, tokString:
+			p.next()
+			segs = append(segs, t.val.(string))
+		default:
+			if segs != nil {
+				return nil, p.errorf(t.pos, "expected a field name after \".\", found %s", t)
+			}
+			return func(x interface{}) (interface{}, error) { return x, nil }, nil
+		}
+	}
+	if p.expr {
+		return p.fieldPath(segs, pos), nil
+	}
+	w := &pathWalker{"query", strings.Join(segs, "."), segs}
+	return func(x interface{}) (interface{}, error) {
+		v, err := w.get(reflect.ValueOf(x))
+		if err != nil {
+			return nil, nil
+		}
+		return v, nil
+	}, nil
+}
+
+func queryConst(v interface{}) queryExpr {
+	return func(interface{}) (interface{}, error) { return v, nil }
+}
+
+// binary combines two expressions with the operator in t.
+func (p *queryParser) binary(t token, lhs, rhs queryExpr) queryExpr {
+	op := t.text
+	if op == "&&" || op == "||" {
+		return func(x interface{}) (interface{}, error) {
+			a, err := lhs(x)
+			if err != nil || truthy(a) == (op == "||") {
+				return truthy(a), err
+			}
+			b, err := rhs(x)
+			return truthy(b), err
+		}
+	}
+	return func(x interface{}) (interface{}, error) {
+		a, err := lhs(x)
+		if err != nil {
+			return nil, err
+		}
+		b, err := rhs(x)
+		if err != nil {
+			return nil, err
+		}
+		// Pointers, such as optional struct fields, stand for what they point
+		// to, or null if they're nil.
+		av, bv := indirect(unwrap(reflect.ValueOf(a))), indirect(unwrap(reflect.ValueOf(b)))
+
+		switch op {
+		case "==", "!=", "<", "<=", ">", ">=":
+			av, bv = coerceText(av, bv), coerceText(bv, av)
+		}
+		switch op {
+		case "==", "!=":
+			c, ok := compareValues(av, bv)
+			equal := ok && c == 0 || !ok && valuesEqual(av, bv)
+			return equal == (op == "=="), nil
+		case "<", "<=", ">", ">=":
+			c, ok := compareValues(av, bv)
+			if !ok {
+				return nil, p.runError(t.pos, "can't compare %s with %s", queryKind(a), queryKind(b))
+			}
+			switch op {
+			case "<":
+				return c < 0, nil
+			case "<=":
+				return c <= 0, nil
+			case ">":
+				return c > 0, nil
+			}
+			return c >= 0, nil
+		}
+
+		if op == "+" && av.Kind() == reflect.String && bv.Kind() == reflect.String {
+			return av.String() + bv.String(), nil
+		}
+		if !isNumber(av) || !isNumber(bv) {
+			return nil, p.runError(t.pos, "can't use %s %s %s", queryKind(a), op, queryKind(b))
+		}
+		m, n := toFloat(av), toFloat(bv)
+		switch op {
+		case "+":
+			return m + n, nil
+		case "-":
+			return m - n, nil
+		case "*":
+			return m * n, nil
+		}
+		if n == 0 {
+			return nil, p.runError(t.pos, "division by zero")
+		}
+		return m / n, nil
+	}
+}
+
+// coerceText returns a string a as the type of b, if that type can be read
+// from text (like time.Time), so that the two can be compared. Otherwise it
+// returns a as it is.
+func coerceText(a, b reflect.Value) reflect.Value {
+	if a.Kind() != reflect.String || !b.IsValid() || b.Kind() == reflect.String {
+		return a
+	}
+	ptr := reflect.New(b.Type())
+	u, ok := ptr.Interface().(encoding.TextUnmarshaler)
+	if !ok || u.UnmarshalText([]byte(a.String())) != nil {
+		return a
+	}
+	return ptr.Elem()
+}
+
+// truthy returns false for nil and false, and true for anything else.
+func truthy(v interface{}) bool {
+	b, isBool := v.(bool)
+	return v != nil && (!isBool || b)
+}
+
+// queryKind describes the kind of a value for error messages.
+func queryKind(v interface{}) string {
+	val := indirect(unwrap(reflect.ValueOf(v)))
+	switch {
+	case !val.IsValid():
+		return "null"
+	case isNumber(val):
+		return "a number"
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a bool"
+	case reflect.Slice, reflect.Array:
+		return "a slice"
+	case reflect.Map:
+		return "a map"
+	}
+	return "a " + val.Type().String()
+}
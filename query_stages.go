@@ -0,0 +1,497 @@
+package tricks
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// A Query is a parsed query, ready to be run on any number of inputs.
+type Query struct {
+	src    string
+	stages []*queryStage
+}
+
+// A queryStage is one stage of a query, with its arguments.
+type queryStage struct {
+	name   string
+	pos    int
+	op     queryOp
+	args   []queryExpr
+	argPos []int
+}
+
+// A queryOp is a kind of stage. Its arguments are expressions, worked out per
+// element or, for arguments like the n of first(n), once against the input.
+type queryOp struct {
+	minArgs, maxArgs int
+	run              func(s *queryStage, in interface{}) (interface{}, error)
+}
+
+var queryOps map[string]queryOp
+
+func init() {
+	queryOps = map[string]queryOp{
+		"filter":   {1, 1, queryFilter},
+		"map":      {1, 1, queryMap},
+		"group_by": {1, 1, queryGroupBy},
+		"count_by": {1, 1, queryCountBy},
+		"sort":     {0, 0, querySort},
+		"sort_by":  {1, 1, querySort},
+		"reverse":  {0, 0, queryReverse},
+		"first":    {0, 1, queryFirstLast},
+		"last":     {0, 1, queryFirstLast},
+		"uniq":     {0, 0, queryUniq},
+		"flatten":  {0, 0, queryFlatten},
+		"join":     {1, 1, queryJoin},
+		"only":     {1, -1, queryOnlyExcept},
+		"except":   {1, -1, queryOnlyExcept},
+		"keys":     {0, 0, queryKeysValues},
+		"values":   {0, 0, queryKeysValues},
+		"count":    {0, 0, queryCount},
+		"sum":      {0, 1, querySum},
+	}
+}
+
+// ParseQuery parses a query, returning a *QuerySyntaxError if it isn't valid.
+// The stages are:
+//
+//	filter(expr)     elements for which expr is true (anything but false or null)
+//	map(expr)        the value of expr for each element
+//	group_by(expr)   a map of elements grouped by the value of expr, as a string
+//	count_by(expr)   a map of how many elements have each value of expr
+//	sort             elements sorted in ascending order
+//	sort_by(expr)    elements sorted by the value of expr
+//	reverse          elements in reverse order
+//	first(n)         the first n elements (or 1, if n is left out)
+//	last(n)          the last n elements (or 1, if n is left out)
+//	uniq             elements with any repeats removed
+//	flatten          elements of nested slices, as one slice
+//	join(sep)        the elements as strings, separated by sep
+//	only(keys...)    a map with only the given keys
+//	except(keys...)  a map without the given keys
+//	keys             the keys of a map, sorted
+//	values           the values of a map, sorted by key
+//	count            the number of elements in a slice or entries in a map
+//	sum(expr)        the sum of expr for each element (or the elements themselves)
+//
+// Sorts are stable, so elements that sort the same keep their order.
+func ParseQuery(query string) (*Query, error) {
+	toks, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{src: query, toks: toks}
+	q := &Query{src: query}
+	for {
+		t := p.next()
+		if t.kind != tokIdent {
+			return nil, p.errorf(t.pos, "expected a stage name, found %s", t)
+		}
+		op, ok := queryOps[t.text]
+		if !ok {
+			return nil, p.errorf(t.pos, "unknown stage %q", t.text)
+		}
+		s := &queryStage{name: t.text, pos: t.pos, op: op}
+		if p.accept("(") && !p.accept(")") {
+			for {
+				s.argPos = append(s.argPos, p.peek().pos)
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				s.args = append(s.args, arg)
+				if p.accept(")") {
+					break
+				}
+				if !p.accept(",") {
+					return nil, p.errorf(p.peek().pos, "expected \",\" or \")\", found %s", p.peek())
+				}
+			}
+		}
+		if n := len(s.args); n < op.minArgs || op.maxArgs >= 0 && n > op.maxArgs {
+			return nil, p.errorf(t.pos, "%s takes %s, not %d", s.name, queryArity(op), n)
+		}
+		q.stages = append(q.stages, s)
+
+		if t := p.next(); t.kind == tokEOF {
+			return q, nil
+		} else if t.kind != tokOp || t.text != "|" {
+			return nil, p.errorf(t.pos, "expected \"|\" or end of query, found %s", t)
+		}
+	}
+}
+
+func queryArity(op queryOp) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return strconv.Itoa(n) + " arguments"
+	}
+	switch {
+	case op.maxArgs < 0:
+		return "at least " + plural(op.minArgs)
+	case op.minArgs == op.maxArgs:
+		return plural(op.minArgs)
+	}
+	return fmt.Sprintf("%d or %s", op.minArgs, plural(op.maxArgs))
+}
+
+// String returns the query as it was written.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Run runs the query on data, which may be a slice or map of any type,
+// including TrickSlices and TrickMaps. The result is a plain value: slices come
+// out as []interface{}, and maps as they are made by the stage that made them.
+// Data is never changed. If a stage can't be run on the output of the one
+// before, Run returns a *QueryError saying which.
+func (q *Query) Run(data interface{}) (interface{}, error) {
+	v := data
+	for _, s := range q.stages {
+		var err error
+		if v, err = s.op.run(s, v); err != nil {
+			if e, ok := err.(*QueryError); ok {
+				// Expressions don't know their stage, nor stages their query.
+				e.Query, e.Stage = q.src, s.name
+			}
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (s *queryStage) errorf(pos int, format string, args ...interface{}) error {
+	return &QueryError{Pos: pos, Stage: s.name, Msg: fmt.Sprintf(format, args...)}
+}
+
+// slice returns the input as a new []interface{}.
+func (s *queryStage) slice(in interface{}) ([]interface{}, error) {
+	v := indirect(unwrap(reflect.ValueOf(in)))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, s.errorf(s.pos, "needs a slice, got %s", queryKind(in))
+	}
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out, nil
+}
+
+// mapping returns the input as a map.
+func (s *queryStage) mapping(in interface{}) (reflect.Value, error) {
+	v := indirect(unwrap(reflect.ValueOf(in)))
+	if v.Kind() != reflect.Map {
+		return reflect.Value{}, s.errorf(s.pos, "needs a map, got %s", queryKind(in))
+	}
+	return v, nil
+}
+
+// each works out the first argument for each element.
+func (s *queryStage) each(items []interface{}) ([]interface{}, error) {
+	out := make([]interface{}, len(items))
+	for i, x := range items {
+		var err error
+		if out[i], err = s.args[0](x); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// eval works out the first argument for x, for use in callbacks that can't
+// return an error. Once *err is set, it stops and returns nil.
+func (s *queryStage) eval(x interface{}, err *error) interface{} {
+	if *err != nil {
+		return nil
+	}
+	v, e := s.args[0](x)
+	*err = e
+	return v
+}
+
+// queryKey makes a map key out of a value: strings stay as they are, and
+// anything else is formatted, so 3 becomes "3" and null becomes "null".
+func queryKey(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func queryFilter(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	out := Slice(items).Filter(func(x interface{}) bool {
+		return truthy(s.eval(x, &err))
+	}).Value()
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func queryMap(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	return s.each(items)
+}
+
+func queryGroupBy(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	out := Slice(items).GroupBy(func(x interface{}) string {
+		return queryKey(s.eval(x, &err))
+	}).Value()
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func queryCountBy(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	out := Slice(items).CountBy(func(x interface{}) string {
+		return queryKey(s.eval(x, &err))
+	}).Value()
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// A querySorter sorts items by their keys, remembering the first pair of keys
+// that couldn't be compared.
+type querySorter struct {
+	items, keys []interface{}
+	err         error
+	stage       *queryStage
+}
+
+func (q *querySorter) Len() int { return len(q.items) }
+
+func (q *querySorter) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.keys[i], q.keys[j] = q.keys[j], q.keys[i]
+}
+
+func (q *querySorter) Less(i, j int) bool {
+	c, ok := compareValues(indirect(reflect.ValueOf(q.keys[i])), indirect(reflect.ValueOf(q.keys[j])))
+	if !ok && q.err == nil {
+		q.err = q.stage.errorf(q.stage.pos, "can't compare %s with %s", queryKind(q.keys[i]), queryKind(q.keys[j]))
+	}
+	return c < 0
+}
+
+func querySort(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	keys := append([]interface{}(nil), items...)
+	if len(s.args) > 0 {
+		if keys, err = s.each(items); err != nil {
+			return nil, err
+		}
+	}
+	sorter := &querySorter{items, keys, nil, s}
+	sort.Stable(sorter)
+	if sorter.err != nil {
+		return nil, sorter.err
+	}
+	return items, nil
+}
+
+func queryReverse(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	return Slice(items).Reverse().Value(), nil
+}
+
+func queryFirstLast(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	n := 1
+	if len(s.args) > 0 {
+		arg, err := s.args[0](in)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := arg.(float64)
+		if !ok || f < 0 || f != math.Trunc(f) {
+			return nil, s.errorf(s.argPos[0], "needs a whole number of elements, got %s", queryKind(arg))
+		}
+		n = int(math.Min(f, float64(len(items))))
+	}
+	if s.name == "first" {
+		return Slice(items).First(n).Value(), nil
+	}
+	return Slice(items).Last(n).Value(), nil
+}
+
+func queryUniq(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[interface{}]bool)
+	out := items[:0]
+	for _, x := range items {
+		v := reflect.ValueOf(x)
+		if v.IsValid() && v.Type().Comparable() {
+			if dup, ok := markSeen(seen, x); ok {
+				if !dup {
+					out = append(out, x)
+				}
+				continue
+			}
+		}
+		if !Slice(out).Any(func(y interface{}) bool { return valuesEqual(v, reflect.ValueOf(y)) }) {
+			out = append(out, x)
+		}
+	}
+	return out, nil
+}
+
+// markSeen adds x to seen, reporting whether it was there already. It returns
+// false if x can't be a map key after all, because it's a struct or array
+// holding an uncomparable value in an interface.
+func markSeen(seen map[interface{}]bool, x interface{}) (dup, ok bool) {
+	defer func() {
+		if recover() != nil {
+			dup, ok = false, false
+		}
+	}()
+	dup = seen[x]
+	seen[x] = true
+	return dup, true
+}
+
+func queryFlatten(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	return Slice(items).Flatten().Value(), nil
+}
+
+func queryJoin(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := s.args[0](in)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sep.(string); !ok {
+		return nil, s.errorf(s.argPos[0], "needs a string to join with, got %s", queryKind(sep))
+	}
+	strs := make([]string, len(items))
+	for i, x := range items {
+		v := indirect(unwrap(reflect.ValueOf(x)))
+		switch v.Kind() {
+		case reflect.Invalid:
+			continue // null joins as an empty string
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			return nil, s.errorf(s.pos, "can't join %s", queryKind(x))
+		}
+		strs[i] = queryKey(v.Interface())
+	}
+	return Slice(strs).Join(sep.(string)), nil
+}
+
+func queryOnlyExcept(s *queryStage, in interface{}) (interface{}, error) {
+	m, err := s.mapping(in)
+	if err != nil {
+		return nil, err
+	}
+	keyType := m.Type().Key()
+	keys := make([]interface{}, len(s.args))
+	for i, arg := range s.args {
+		v, err := arg(in)
+		if err != nil {
+			return nil, err
+		}
+		key := reflect.New(keyType).Elem()
+		if keyType.Kind() == reflect.String {
+			key.SetString(queryKey(v))
+		} else if convertInto(key, reflect.ValueOf(v), "") != nil {
+			return nil, s.errorf(s.argPos[i], "can't use %s as a key of type %s", queryKind(v), keyType)
+		}
+		keys[i] = key.Interface()
+	}
+	if s.name == "only" {
+		return TrickMap(m).Only(keys...).Value(), nil
+	}
+	return TrickMap(m).Except(keys...).Value(), nil
+}
+
+func queryKeysValues(s *queryStage, in interface{}) (interface{}, error) {
+	m, err := s.mapping(in)
+	if err != nil {
+		return nil, err
+	}
+	keys := sortedKeys(m)
+	out := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if s.name == "keys" {
+			out[i] = key.Interface()
+		} else {
+			out[i] = m.MapIndex(key).Interface()
+		}
+	}
+	return out, nil
+}
+
+func queryCount(s *queryStage, in interface{}) (interface{}, error) {
+	v := indirect(unwrap(reflect.ValueOf(in)))
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), nil
+	}
+	return nil, s.errorf(s.pos, "needs a slice or map, got %s", queryKind(in))
+}
+
+func querySum(s *queryStage, in interface{}) (interface{}, error) {
+	items, err := s.slice(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.args) > 0 {
+		if items, err = s.each(items); err != nil {
+			return nil, err
+		}
+	}
+	var sum float64
+	for _, x := range items {
+		v := reflect.ValueOf(x)
+		if !isNumber(v) {
+			return nil, s.errorf(s.pos, "can't add up %s", queryKind(x))
+		}
+		sum += toFloat(v)
+	}
+	return sum, nil
+}
@@ -0,0 +1,209 @@
+package tricks
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type queryCase struct {
+	line         int
+	query        string
+	want, errMsg string
+}
+
+// readQueryCorpus reads the cases in testdata/query/corpus.txt.
+func readQueryCorpus(t *testing.T) []queryCase {
+	f, err := os.Open("testdata/query/corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var cases []queryCase
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "query: "):
+			cases = append(cases, queryCase{line: n, query: strings.TrimPrefix(line, "query: ")})
+		case strings.HasPrefix(line, "want: "):
+			cases[len(cases)-1].want = strings.TrimSpace(strings.TrimPrefix(line, "want: "))
+		case strings.HasPrefix(line, "error: "):
+			cases[len(cases)-1].errMsg = strings.TrimPrefix(line, "error: ")
+		case line != "" && !strings.HasPrefix(line, "#"):
+			t.Fatalf("corpus.txt:%d: can't read %q", n, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return cases
+}
+
+func testRequests(t *testing.T) []interface{} {
+	data, err := ioutil.ReadFile("testdata/query/requests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []interface{}
+	if err := json.Unmarshal(data, &requests); err != nil {
+		t.Fatal(err)
+	}
+	return requests
+}
+
+// jsonValue round-trips v through JSON, so that results can be compared with
+// what the corpus expects regardless of the exact Go types.
+func jsonValue(t *testing.T, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestQueryCorpus(t *testing.T) {
+	cases := readQueryCorpus(t)
+	assert.NotEmpty(t, cases)
+	for _, c := range cases {
+		requests := testRequests(t)
+		before := jsonValue(t, requests)
+
+		q, err := ParseQuery(c.query)
+		if err == nil {
+			var got interface{}
+			if got, err = q.Run(requests); err == nil {
+				var want interface{}
+				if err := json.Unmarshal([]byte(c.want), &want); err != nil {
+					t.Fatalf("corpus.txt:%d: bad want: %v", c.line, err)
+				}
+				assert.Equal(t, want, jsonValue(t, got), "corpus.txt:%d: %s", c.line, c.query)
+			}
+		}
+		if c.errMsg != "" {
+			assert.EqualError(t, err, c.errMsg, "corpus.txt:%d: %s", c.line, c.query)
+		} else {
+			assert.NoError(t, err, "corpus.txt:%d: %s", c.line, c.query)
+		}
+		assert.Equal(t, before, jsonValue(t, requests), "corpus.txt:%d: input was changed", c.line)
+	}
+}
+
+func TestQueryErrorTypes(t *testing.T) {
+	_, err := ParseQuery(`map(.a) | bogus`)
+	syntaxErr, ok := err.(*QuerySyntaxError)
+	if assert.True(t, ok) {
+		assert.Equal(t, 10, syntaxErr.Pos)
+	}
+
+	q, err := ParseQuery(`map(.a) | keys`)
+	assert.NoError(t, err)
+	_, err = q.Run([]int{1, 2})
+	queryErr, ok := err.(*QueryError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "keys", queryErr.Stage)
+		assert.Equal(t, 10, queryErr.Pos)
+		assert.Equal(t, `map(.a) | keys`, queryErr.Query)
+	}
+
+	// Columns count characters, not bytes.
+	_, err = ParseQuery(`filter(.name == "café") |`)
+	assert.EqualError(t, err, "tricks: query: syntax error at column 26: expected a stage name, found end of query")
+}
+
+func TestQueryTypedData(t *testing.T) {
+	accounts := testAccounts()
+	q, err := ParseQuery(`filter(.Status == "active") | sort_by(.Age) | map(.Name)`)
+	assert.NoError(t, err)
+	assert.Equal(t, `filter(.Status == "active") | sort_by(.Age) | map(.Name)`, q.String())
+
+	got, err := q.Run(accounts)
+	assert.NoError(t, err)
+	want, err := q.Run(Slice(accounts))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, []interface{}{"cat", "ann"}, got)
+
+	// Pointers are followed, and nil ones are null.
+	type item struct {
+		ID int
+		N  *int
+	}
+	two, three := 2, 3
+	items := []item{{1, &three}, {2, nil}, {3, &two}}
+	for query, want := range map[string]interface{}{
+		`filter(.N > 2) | map(.ID)`:         []interface{}{1},
+		`filter(.N == 3) | map(.ID)`:        []interface{}{1},
+		`filter(.N == null) | map(.ID)`:     []interface{}{2},
+		`filter(.N != null) | map(.N * 10)`: []interface{}{30.0, 20.0},
+		`sort_by(.N) | map(.ID)`:            []interface{}{2, 3, 1},
+	} {
+		q, err = ParseQuery(query)
+		assert.NoError(t, err, query)
+		got, err = q.Run(items)
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, got, query)
+	}
+
+	// Pointers are joined by what they point to.
+	a, c := "a", "c"
+	q, err = ParseQuery(`join(",")`)
+	assert.NoError(t, err)
+	got, err = q.Run([]*string{&a, nil, &c})
+	assert.NoError(t, err)
+	assert.Equal(t, "a,,c", got)
+
+	// Structs holding slices can't be map keys, but are still compared.
+	type box struct{ A interface{} }
+	q, err = ParseQuery(`uniq`)
+	assert.NoError(t, err)
+	got, err = q.Run([]box{{[]int{1}}, {1}, {[]int{1}}, {1}, {[]int{2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{box{[]int{1}}, box{1}, box{[]int{2}}}, got)
+
+	q, err = ParseQuery(`only(2, 3) | keys`)
+	assert.NoError(t, err)
+	got, err = q.Run(map[int]string{1: "a", 2: "b", 3: "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{2, 3}, got)
+}
+
+func TestQueryExpressions(t *testing.T) {
+	cases := []struct {
+		expr string
+		want interface{}
+	}{
+		{`1 + 2 * 3`, 7.0},
+		{`(1 + 2) * 3`, 9.0},
+		{`10 - 4 - 3`, 3.0},
+		{`-2.5e1`, -25.0},
+		{`"a" + 'b'`, "ab"},
+		{`"it\'s" + "\tx"`, "it's\tx"},
+		{`1 < 2 && 2 < 3`, true},
+		{`false || null`, false},
+		{`!null`, true},
+		{`. == 4`, true},
+		{`null == null`, true},
+		{`"1" == 1`, false},
+		{`0`, 0.0},
+	}
+	for _, c := range cases {
+		q, err := ParseQuery("map(" + c.expr + ")")
+		if !assert.NoError(t, err, c.expr) {
+			continue
+		}
+		got, err := q.Run([]int{4})
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, []interface{}{c.want}, got, c.expr)
+	}
+}
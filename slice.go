@@ -9,6 +9,10 @@ var (
 	typeInterface  = reflect.TypeOf((*interface{})(nil)).Elem() // interface{}
 )
 
+// Slice wraps a slice, or makes a new one from the given elements. Arrays are
+// accepted too: an array passed by value is copied into a new slice, since
+// there's no way to reach the original, while a pointer to an array is sliced
+// in place, so changes made through the TrickSlice are seen in the array.
 func Slice(sliceOrElements ...interface{}) TrickSlice {
 	s := reflect.ValueOf(sliceOrElements) // []interface{}
 
@@ -24,6 +28,14 @@ func Slice(sliceOrElements ...interface{}) TrickSlice {
 		if v.Kind() == reflect.Slice { // TrickSlice is a Kind of reflect.Struct
 			return TrickSlice(v)
 		}
+		if v.Kind() == reflect.Array {
+			out := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), v.Len(), v.Len())
+			reflect.Copy(out, v)
+			return TrickSlice(out)
+		}
+		if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Array && !v.IsNil() {
+			return TrickSlice(v.Elem().Slice(0, v.Elem().Len()))
+		}
 		if v.Type() == typeTrickSlice {
 			return sliceOrElements[0].(TrickSlice)
 		}
@@ -106,9 +118,10 @@ func (ts TrickSlice) Last(n int) TrickSlice {
 }
 
 // Flatten returns a new slice of values, recursively extracting the elements
-// from any nested slices. This new slice tries to take on the type of the first
-// non-slice element encountered. If the elements are of mixed types, it falls
-// back to []interface{}. nil values are treated as zeroes of the common type.
+// from any nested slices, arrays or TrickSlices, including those held in
+// interfaces. If all the non-nil values found are of the same type, the new
+// slice is of that type, and any nils become zeroes of it. Otherwise, it's a
+// []interface{}.
 func (ts TrickSlice) Flatten() TrickSlice {
 	in := reflect.Value(ts)
 
@@ -117,7 +130,7 @@ func (ts TrickSlice) Flatten() TrickSlice {
 
 	var extract, extractSlice func(reflect.Value)
 	extract = func(el reflect.Value) {
-		if el.Kind() == reflect.Slice {
+		if el.Kind() == reflect.Slice || el.Kind() == reflect.Array {
 			extractSlice(el)
 			return
 		}
@@ -140,7 +153,7 @@ func (ts TrickSlice) Flatten() TrickSlice {
 		vals = append(vals, el)
 	}
 	extractSlice = func(slice reflect.Value) {
-		// Invariant: slice.Type().Kind() == reflect.Slice
+		// Invariant: slice.Type().Kind() is reflect.Slice or reflect.Array
 		for i := 0; i < slice.Len(); i++ {
 			extract(slice.Index(i))
 		}
@@ -0,0 +1,135 @@
+package tricks
+
+import "reflect"
+
+func keyBy(context string, ts TrickSlice, fn interface{}, unique bool) (TrickMap, error) {
+	v := reflect.Value(ts)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapFunc(f.Type(), v.Type()) {
+		panic("tricks: " + context + ": invalid function type")
+	}
+	keyType := f.Type().Out(0)
+	if !keyType.Comparable() {
+		panic("tricks: " + context + ": key type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(keyType, v.Type().Elem()))
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		key := f.Call([]reflect.Value{val})[0]
+		if unique && out.MapIndex(key).IsValid() {
+			return TrickMap{}, &DuplicateKeyError{context, key.Interface()}
+		}
+		out.SetMapIndex(key, val)
+	}
+
+	return TrickMap(out), nil
+}
+
+// KeyBy collects the slice values into a map, where the keys are the return
+// value of the given function. Unlike GroupBy, each key holds a single element;
+// if more than one element produces the same key, the last one wins.
+func (ts TrickSlice) KeyBy(fn interface{}) TrickMap {
+	tm, _ := keyBy("slice.KeyBy", ts, fn, false)
+	return tm
+}
+
+// KeyByUnique is like KeyBy, but returns a *DuplicateKeyError if more than one
+// element produces the same key.
+func (ts TrickSlice) KeyByUnique(fn interface{}) (TrickMap, error) {
+	return keyBy("slice.KeyByUnique", ts, fn, true)
+}
+
+// ToMap builds a map from the slice, applying keyFn and valFn to each element
+// to get its key and value. keyFn should be `func(T) K` and valFn `func(T) V`.
+// If more than one element produces the same key, the last one wins.
+func (ts TrickSlice) ToMap(keyFn, valFn interface{}) TrickMap {
+	v := reflect.Value(ts)
+	kf := reflect.ValueOf(keyFn)
+	vf := reflect.ValueOf(valFn)
+	if !kf.IsValid() || !isValidMapFunc(kf.Type(), v.Type()) ||
+		!vf.IsValid() || !isValidMapFunc(vf.Type(), v.Type()) {
+		panic("tricks: slice.ToMap: invalid function type")
+	}
+	keyType := kf.Type().Out(0)
+	if !keyType.Comparable() {
+		panic("tricks: slice.ToMap: key type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(keyType, vf.Type().Out(0)))
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		out.SetMapIndex(
+			kf.Call([]reflect.Value{val})[0],
+			vf.Call([]reflect.Value{val})[0])
+	}
+
+	return TrickMap(out)
+}
+
+// pairTypes returns the key and value types of a key/value pair type, or nils
+// if the type can't hold a pair.
+func pairTypes(typ reflect.Type) (reflect.Type, reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Struct:
+		if typ.NumField() == 2 && typ.Field(0).PkgPath == "" && typ.Field(1).PkgPath == "" {
+			return typ.Field(0).Type, typ.Field(1).Type
+		}
+	case reflect.Array:
+		if typ.Len() == 2 {
+			return typ.Elem(), typ.Elem()
+		}
+	case reflect.Slice:
+		return typ.Elem(), typ.Elem()
+	case reflect.Interface:
+		return typeInterface, typeInterface
+	}
+	return nil, nil
+}
+
+// splitPair returns the key and value held by a pair, and whether it is one.
+func splitPair(pair reflect.Value) (reflect.Value, reflect.Value, bool) {
+	if pair.Kind() == reflect.Interface {
+		pair = pair.Elem()
+	}
+	switch pair.Kind() {
+	case reflect.Struct:
+		if pair.NumField() == 2 {
+			return pair.Field(0), pair.Field(1), true
+		}
+	case reflect.Array, reflect.Slice:
+		if pair.Len() == 2 {
+			return pair.Index(0), pair.Index(1), true
+		}
+	}
+	return reflect.Value{}, reflect.Value{}, false
+}
+
+// Associate builds a map from a slice of key/value pairs. Each element must be
+// either a struct with exactly two exported fields (the key, then the value),
+// or an array or slice of length 2. If more than one pair has the same key, the
+// last one wins.
+//
+// For example, a []struct{ K string; V int } becomes a map[string]int, and a
+// [][2]string becomes a map[string]string.
+func (ts TrickSlice) Associate() TrickMap {
+	v := reflect.Value(ts)
+	keyType, valType := pairTypes(v.Type().Elem())
+	if keyType == nil {
+		panic("tricks: slice.Associate: elements are not key/value pairs")
+	}
+	if !keyType.Comparable() {
+		panic("tricks: slice.Associate: key type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(keyType, valType))
+	for i := 0; i < v.Len(); i++ {
+		key, val, ok := splitPair(v.Index(i))
+		if !ok {
+			panic("tricks: slice.Associate: elements are not key/value pairs")
+		}
+		out.SetMapIndex(key, val)
+	}
+
+	return TrickMap(out)
+}
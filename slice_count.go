@@ -0,0 +1,128 @@
+package tricks
+
+import (
+	"reflect"
+	"sort"
+)
+
+var typeInt = reflect.TypeOf((*int)(nil)).Elem() // int
+
+// addCount adds n to the count stored against key in a map[K]int.
+func addCount(counts, key reflect.Value, n int) {
+	c := counts.MapIndex(key)
+	if c.IsValid() {
+		n += int(c.Int())
+	}
+	counts.SetMapIndex(key, reflect.ValueOf(n))
+}
+
+// tally counts the distinct elements of the slice v. It returns the elements
+// in the order they were first seen, along with a map[T]int of their counts.
+func tally(context string, v reflect.Value) ([]reflect.Value, reflect.Value) {
+	elemType := v.Type().Elem()
+	if !elemType.Comparable() {
+		panic("tricks: " + context + ": elements are not comparable")
+	}
+
+	var seen []reflect.Value
+	counts := reflect.MakeMap(reflect.MapOf(elemType, typeInt))
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		if !counts.MapIndex(val).IsValid() {
+			seen = append(seen, val)
+		}
+		addCount(counts, val, 1)
+	}
+	return seen, counts
+}
+
+// byCountDesc sorts keys by their counts, most frequent first.
+type byCountDesc struct {
+	keys   []reflect.Value
+	counts reflect.Value // map[K]int
+}
+
+func (s *byCountDesc) Len() int      { return len(s.keys) }
+func (s *byCountDesc) Swap(i, j int) { s.keys[i], s.keys[j] = s.keys[j], s.keys[i] }
+func (s *byCountDesc) Less(i, j int) bool {
+	return s.counts.MapIndex(s.keys[i]).Int() > s.counts.MapIndex(s.keys[j]).Int()
+}
+
+// mostCommon returns a slice of type sliceType holding the n keys with the
+// highest counts, in descending order of count. Ties keep the order of keys.
+func mostCommon(context string, sliceType reflect.Type, keys []reflect.Value, counts reflect.Value, n int) reflect.Value {
+	if n < 0 {
+		panic("tricks: " + context + ": n is negative")
+	}
+	sort.Stable(&byCountDesc{keys, counts})
+	if n > len(keys) {
+		n = len(keys)
+	}
+	out := reflect.MakeSlice(sliceType, n, n)
+	for i := 0; i < n; i++ {
+		out.Index(i).Set(keys[i])
+	}
+	return out
+}
+
+// Count returns the number of elements in the slice for which the given
+// function returns true.
+func (ts TrickSlice) Count(fn interface{}) int {
+	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
+		panic("tricks: slice.Count: invalid function type")
+	}
+
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		if f.Call([]reflect.Value{val})[0].Bool() {
+			n++
+		}
+	}
+	return n
+}
+
+// CountBy counts the slice values into a map, where the keys are the return
+// value of the given function and the values are the number of elements that
+// correspond to that key.
+func (ts TrickSlice) CountBy(fn interface{}) TrickMap {
+	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprKey)
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapFunc(f.Type(), v.Type()) {
+		panic("tricks: slice.CountBy: invalid function type")
+	}
+	keyType := f.Type().Out(0)
+	if !keyType.Comparable() {
+		panic("tricks: slice.CountBy: key type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(keyType, typeInt))
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		addCount(out, f.Call([]reflect.Value{val})[0], 1)
+	}
+
+	return TrickMap(out)
+}
+
+// Tally counts the occurrences of each distinct element in the slice, returning
+// a map of each element to its count. The elements must be comparable.
+func (ts TrickSlice) Tally() TrickMap {
+	_, counts := tally("slice.Tally", reflect.Value(ts))
+	return TrickMap(counts)
+}
+
+// MostCommon returns a new slice of the n most frequent distinct elements, in
+// descending order of frequency. Elements that occur equally often are ordered
+// by their first appearance in the slice. If n > number of distinct elements,
+// all of them are returned. The elements must be comparable, and n must not be
+// negative.
+func (ts TrickSlice) MostCommon(n int) TrickSlice {
+	v := reflect.Value(ts)
+	keys, counts := tally("slice.MostCommon", v)
+	return TrickSlice(mostCommon("slice.MostCommon", v.Type(), keys, counts, n))
+}
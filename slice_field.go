@@ -0,0 +1,162 @@
+package tricks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// sliceFieldType resolves the field path against the slice's element type,
+// panicking if the field doesn't exist or isn't exported.
+func sliceFieldType(context string, v reflect.Value, path []string) reflect.Type {
+	typ, err := fieldType(v.Type().Elem(), path)
+	if err != nil {
+		panic("tricks: " + context + ": " + err.Error())
+	}
+	return typ
+}
+
+// sliceFieldValue gets the field at path from el. If there's a nil pointer (or
+// missing map key) on the way to it, the zero value of typ is returned.
+func sliceFieldValue(context string, el reflect.Value, path []string, typ reflect.Type) reflect.Value {
+	f, err := fieldValue(el, path)
+	if err != nil {
+		panic("tricks: " + context + ": " + err.Error())
+	}
+	if !f.IsValid() {
+		return reflect.Zero(typ)
+	}
+	return f
+}
+
+// Pluck returns a new slice of the values of the named field of each element.
+// Fields may be nested, like "Address.City", passing through pointers and
+// embedded structs; if there's a nil pointer along the way, the zero value is
+// used. Slices of maps with string keys can be plucked by key in the same way.
+// Pluck panics if the field doesn't exist or isn't exported.
+func (ts TrickSlice) Pluck(field string) TrickSlice {
+	v := reflect.Value(ts)
+	path := splitFieldPath(field)
+	typ := sliceFieldType("slice.Pluck", v, path)
+
+	out := reflect.MakeSlice(reflect.SliceOf(typ), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		out.Index(i).Set(sliceFieldValue("slice.Pluck", v.Index(i), path, typ))
+	}
+
+	return TrickSlice(out)
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// looselyEqual reports whether two values are equal, ignoring differences in
+// type between numbers (so int64(2) == 2.0), and between strings or bools of
+// different named types. Anything else is compared with reflect.DeepEqual.
+func looselyEqual(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if isNil(a) || isNil(b) {
+		return isNil(a) && isNil(b)
+	}
+	if c, ok := compareValues(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// Where returns a new slice, choosing only the elements whose named field is
+// equal to value. Numbers are compared by value regardless of their type, so
+// Where("Age", 42) matches an int64 field. Fields are named as for Pluck.
+func (ts TrickSlice) Where(field string, value interface{}) TrickSlice {
+	v := reflect.Value(ts)
+	path := splitFieldPath(field)
+	typ := sliceFieldType("slice.Where", v, path)
+	want := reflect.ValueOf(value)
+
+	out := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		if looselyEqual(sliceFieldValue("slice.Where", val, path, typ), want) {
+			out = reflect.Append(out, val)
+		}
+	}
+
+	return TrickSlice(out)
+}
+
+type sortableByField struct {
+	val  reflect.Value
+	keys []reflect.Value
+	desc bool
+}
+
+func (s *sortableByField) Len() int {
+	return s.val.Len()
+}
+
+func (s *sortableByField) Swap(i, j int) {
+	swapValue(s.val, i, j)
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s *sortableByField) Less(i, j int) bool {
+	c, ok := compareValues(s.keys[i], s.keys[j])
+	if !ok {
+		panic(fmt.Sprintf("tricks: slice.SortByField: can't compare %v with %v",
+			s.keys[i].Type(), s.keys[j].Type()))
+	}
+	if s.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// SortByField sorts the slice in place by the named field of each element, in
+// ascending order, or descending if desc is true. The sort is stable. Fields
+// are named as for Pluck, and must hold numbers, strings, bools or time.Time.
+func (ts TrickSlice) SortByField(field string, desc bool) TrickSlice {
+	v := reflect.Value(ts)
+	path := splitFieldPath(field)
+	typ := sliceFieldType("slice.SortByField", v, path)
+
+	keys := make([]reflect.Value, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		// Copy the keys out, so they don't move when the elements are swapped.
+		key := sliceFieldValue("slice.SortByField", v.Index(i), path, typ)
+		keys[i] = reflect.ValueOf(key.Interface())
+	}
+	sort.Stable(&sortableByField{v, keys, desc})
+	return ts
+}
+
+// GroupByField collects the slice values into a map, where the keys are the
+// values of the named field and the values are slices of elements that have
+// that field value. Fields are named as for Pluck.
+func (ts TrickSlice) GroupByField(field string) TrickMap {
+	v := reflect.Value(ts)
+	path := splitFieldPath(field)
+	keyType := sliceFieldType("slice.GroupByField", v, path)
+	if !keyType.Comparable() {
+		panic("tricks: slice.GroupByField: field type is not comparable")
+	}
+
+	out := reflect.MakeMap(reflect.MapOf(keyType, v.Type()))
+	for i := 0; i < v.Len(); i++ {
+		val := v.Index(i)
+		appendGroup(out, sliceFieldValue("slice.GroupByField", val, path, keyType), val)
+	}
+
+	return TrickMap(out)
+}
@@ -22,6 +22,7 @@ func isValidBoolFunc(funcType, sliceType reflect.Type) bool {
 // slice. Otherwise, it returns false.
 func (ts TrickSlice) Any(fn interface{}) bool {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.Any: invalid function type")
@@ -40,6 +41,7 @@ func (ts TrickSlice) Any(fn interface{}) bool {
 // slice. Otherwise, it returns false.
 func (ts TrickSlice) All(fn interface{}) bool {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.All: invalid function type")
@@ -58,6 +60,7 @@ func (ts TrickSlice) All(fn interface{}) bool {
 // slice. Otherwise, it returns false.
 func (ts TrickSlice) None(fn interface{}) bool {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.None: invalid function type")
@@ -76,6 +79,7 @@ func (ts TrickSlice) None(fn interface{}) bool {
 // in the slice. Otherwise, it returns false.
 func (ts TrickSlice) One(fn interface{}) bool {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.One: invalid function type")
@@ -98,6 +102,7 @@ func (ts TrickSlice) One(fn interface{}) bool {
 // in the slice. Otherwise, it returns false.
 func (ts TrickSlice) Many(fn interface{}) bool {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.Many: invalid function type")
@@ -120,6 +125,7 @@ func (ts TrickSlice) Many(fn interface{}) bool {
 // function returns true.
 func (ts TrickSlice) Filter(fn interface{}) TrickSlice {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprTest)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidBoolFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.Filter: invalid function type")
@@ -141,6 +147,7 @@ func (ts TrickSlice) Filter(fn interface{}) TrickSlice {
 // result to a new slice. The cap() of the new slice is set to equal its length.
 func (ts TrickSlice) Map(fn interface{}) TrickSlice {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprValue)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidMapFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.Map: invalid function type")
@@ -189,6 +196,7 @@ func (ts TrickSlice) Reduce(zero, fn interface{}) interface{} {
 // correspond to that key.
 func (ts TrickSlice) GroupBy(fn interface{}) TrickMap {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprKey)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidMapFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.GroupBy: invalid function type")
@@ -200,13 +208,18 @@ func (ts TrickSlice) GroupBy(fn interface{}) TrickMap {
 	out := reflect.MakeMap(mapType)
 	for i := 0; i < v.Len(); i++ {
 		val := v.Index(i)
-		key := f.Call([]reflect.Value{val})[0]
-		group := out.MapIndex(key)
-		if !group.IsValid() {
-			group = reflect.MakeSlice(valType, 0, 1)
-		}
-		out.SetMapIndex(key, reflect.Append(group, val))
+		appendGroup(out, f.Call([]reflect.Value{val})[0], val)
 	}
 
 	return TrickMap(out)
 }
+
+// appendGroup appends val to the slice stored against key in a map[K][]V,
+// starting a new slice if there isn't one yet.
+func appendGroup(groups, key, val reflect.Value) {
+	group := groups.MapIndex(key)
+	if !group.IsValid() {
+		group = reflect.MakeSlice(groups.Type().Elem(), 0, 1)
+	}
+	groups.SetMapIndex(key, reflect.Append(group, val))
+}
@@ -4,6 +4,8 @@ import (
 	"math"
 	"reflect"
 	"sort"
+	"strings"
+	"time"
 )
 
 func swapValue(v reflect.Value, i, j int) {
@@ -184,6 +186,7 @@ func isValidSortByFunc(funcType, sliceType reflect.Type) bool {
 // whether element `a < b`.
 func (ts TrickSlice) SortBy(fn interface{}) TrickSlice {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprLess)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidSortByFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.SortBy: invalid function type")
@@ -197,6 +200,7 @@ func (ts TrickSlice) SortBy(fn interface{}) TrickSlice {
 // If the slice is empty, this method returns the nil interface{}.
 func (ts TrickSlice) MinBy(fn interface{}) interface{} {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprLess)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidSortByFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.MinBy: invalid function type")
@@ -209,9 +213,117 @@ func (ts TrickSlice) MinBy(fn interface{}) interface{} {
 // If the slice is empty, this method returns the nil interface{}.
 func (ts TrickSlice) MaxBy(fn interface{}) interface{} {
 	v := reflect.Value(ts)
+	fn = exprCallback(fn, v.Type(), exprLess)
 	f := reflect.ValueOf(fn)
 	if !f.IsValid() || !isValidSortByFunc(f.Type(), v.Type()) {
 		panic("tricks: slice.MaxBy: invalid function type")
 	}
 	return v.Index(findIndexMax(&sortableBy{v, fn})).Interface()
 }
+
+var typeTime = reflect.TypeOf(time.Time{}) // time.Time
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b, math.IsNaN(a) && !math.IsNaN(b):
+		return -1
+	case a > b, math.IsNaN(b) && !math.IsNaN(a):
+		return 1
+	}
+	return 0
+}
+
+// compareValues orders two values of a similar kind (numbers, strings, bools
+// or times), returning -1, 0 or +1. Interfaces are unwrapped first, and nil
+// values are ordered before everything else. It returns false if the values
+// can't be ordered against each other.
+func compareValues(a, b reflect.Value) (int, bool) {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0, true
+	case !a.IsValid():
+		return -1, true
+	case !b.IsValid():
+		return 1, true
+	}
+
+	switch {
+	case isNumber(a) && isNumber(b):
+		ak, bk := a.Kind(), b.Kind()
+		if ak >= reflect.Int && ak <= reflect.Int64 && bk >= reflect.Int && bk <= reflect.Int64 {
+			return compareInts(a.Int(), b.Int()), true
+		}
+		if ak >= reflect.Uint && ak <= reflect.Uintptr && bk >= reflect.Uint && bk <= reflect.Uintptr {
+			return compareUints(a.Uint(), b.Uint()), true
+		}
+		return compareFloats(toFloat(a), toFloat(b)), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return compareInts(boolToInt(a.Bool()), boolToInt(b.Bool())), true
+	case a.Type() == typeTime && b.Type() == typeTime:
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1, true
+		case ta.After(tb):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package tricks
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -588,3 +589,248 @@ func TestSliceDelete(t *testing.T) {
 	runes.Delete(0)
 	assert.Panics(t, func() { runes.Delete(0) })
 }
+
+func TestSliceCount(t *testing.T) {
+	creatures := Slice("ant", "bear", "cat")
+	assert.Equal(t, 2, creatures.Count(func(word string) bool { return len(word) < 4 }))
+	assert.Equal(t, 0, creatures.Count(func(word string) bool { return len(word) > 4 }))
+	assert.Equal(t, 0, Slice([]string{}).Count(func(word string) bool { return true }))
+
+	assert.Panics(t, func() { creatures.Count(func(i int) bool { return true }) })
+}
+
+func TestSliceCountBy(t *testing.T) {
+	var animals = []string{"dog", "cat", "bear", "cow", "bull", "pig", "iguana"}
+
+	counts := Slice(animals).
+		CountBy(func(s string) int { return len(s) }).
+		Value().(map[int]int)
+
+	assert.Equal(t, map[int]int{3: 4, 4: 2, 6: 1}, counts)
+
+	assert.Panics(t, func() { Slice(animals).CountBy(func(s string) []int { return nil }) })
+}
+
+func TestSliceTally(t *testing.T) {
+	var levels = []string{"info", "warn", "info", "error", "info", "warn"}
+
+	tally := Slice(levels).Tally().Value().(map[string]int)
+	assert.Equal(t, map[string]int{"info": 3, "warn": 2, "error": 1}, tally)
+
+	empty := Slice([]int{}).Tally().Value().(map[int]int)
+	assert.Equal(t, map[int]int{}, empty)
+
+	assert.Panics(t, func() { Slice([][]int{{1}, {2}}).Tally() })
+}
+
+func TestSliceMostCommon(t *testing.T) {
+	var levels = []string{"debug", "warn", "info", "error", "info", "warn", "info"}
+
+	assert.Equal(t, []string{"info", "warn"}, Slice(levels).MostCommon(2).Value().([]string))
+	assert.Equal(t,
+		[]string{"info", "warn", "debug", "error"},
+		Slice(levels).MostCommon(10).Value().([]string))
+	assert.Equal(t, []string{}, Slice(levels).MostCommon(0).Value().([]string))
+	assert.Equal(t, []int{}, Slice([]int{}).MostCommon(3).Value().([]int))
+	assert.PanicsWithValue(t, "tricks: slice.MostCommon: n is negative", func() { Slice(levels).MostCommon(-1) })
+}
+
+type testUser struct {
+	ID   int
+	Name string
+}
+
+func TestSliceKeyBy(t *testing.T) {
+	users := []testUser{{1, "ann"}, {2, "bob"}, {3, "ann"}}
+
+	byID := Slice(users).KeyBy(func(u testUser) int { return u.ID }).Value().(map[int]testUser)
+	assert.Equal(t, map[int]testUser{1: users[0], 2: users[1], 3: users[2]}, byID)
+
+	byName := Slice(users).KeyBy(func(u testUser) string { return u.Name }).Value().(map[string]testUser)
+	assert.Equal(t, map[string]testUser{"ann": users[2], "bob": users[1]}, byName)
+
+	assert.Panics(t, func() { Slice(users).KeyBy(func(s string) string { return s }) })
+}
+
+func TestSliceKeyByUnique(t *testing.T) {
+	users := []testUser{{1, "ann"}, {2, "bob"}, {3, "ann"}}
+
+	byID, err := Slice(users).KeyByUnique(func(u testUser) int { return u.ID })
+	assert.NoError(t, err)
+	assert.Equal(t, 3, byID.Len())
+
+	_, err = Slice(users).KeyByUnique(func(u testUser) string { return u.Name })
+	assert.Equal(t, &DuplicateKeyError{"slice.KeyByUnique", "ann"}, err)
+	assert.EqualError(t, err, "tricks: slice.KeyByUnique: duplicate key ann")
+}
+
+func TestSliceToMap(t *testing.T) {
+	users := []testUser{{1, "ann"}, {2, "bob"}}
+
+	names := Slice(users).
+		ToMap(func(u testUser) int { return u.ID }, func(u testUser) string { return u.Name }).
+		Value().(map[int]string)
+	assert.Equal(t, map[int]string{1: "ann", 2: "bob"}, names)
+
+	assert.True(t, Slice(users).
+		ToMap(func(u testUser) string { return u.Name }, func(u testUser) int { return u.ID }).
+		HasKeys("ann", "bob"))
+
+	assert.Panics(t, func() {
+		Slice(users).ToMap(func(u testUser) int { return u.ID }, func(i int) int { return i })
+	})
+}
+
+func TestSliceAssociate(t *testing.T) {
+	type pair struct {
+		Name string
+		Age  int
+	}
+	ages := Slice(pair{"ann", 31}, pair{"bob", 42}).Associate().Value().(map[string]int)
+	assert.Equal(t, map[string]int{"ann": 31, "bob": 42}, ages)
+
+	arrays := Slice([][2]string{{"a", "Apple"}, {"b", "Ball"}}).Associate().Value().(map[string]string)
+	assert.Equal(t, map[string]string{"a": "Apple", "b": "Ball"}, arrays)
+
+	slices := Slice([][]int{{1, 10}, {2, 20}, {1, 30}}).Associate().Value().(map[int]int)
+	assert.Equal(t, map[int]int{1: 30, 2: 20}, slices)
+
+	mixed := Slice([]interface{}{[]interface{}{"a", 1}, [2]string{"b", "c"}}).Associate()
+	assert.Equal(t, map[interface{}]interface{}{"a": 1, "b": "c"}, mixed.Value())
+
+	assert.Panics(t, func() { Slice(1, 2, 3).Associate() })
+	assert.Panics(t, func() { Slice([][]int{{1, 2, 3}}).Associate() })
+	assert.Panics(t, func() { Slice(struct{ a, b int }{1, 2}).Associate() })
+}
+
+type testAddress struct {
+	City    string
+	Country string
+}
+
+type testAudit struct {
+	CreatedAt time.Time
+}
+
+type testAccount struct {
+	testAudit
+	Name    string
+	Status  string
+	Age     int64
+	Address *testAddress
+	secret  string
+}
+
+func testAccounts() []testAccount {
+	day := func(d int) testAudit { return testAudit{time.Date(2016, 10, d, 0, 0, 0, 0, time.UTC)} }
+	return []testAccount{
+		{day(3), "ann", "active", 31, &testAddress{"Auckland", "NZ"}, ""},
+		{day(1), "bob", "closed", 42, nil, ""},
+		{day(2), "cat", "active", 27, &testAddress{"Sydney", "AU"}, ""},
+	}
+}
+
+func TestSlicePluck(t *testing.T) {
+	accounts := testAccounts()
+
+	assert.Equal(t, []string{"ann", "bob", "cat"}, Slice(accounts).Pluck("Name").Value().([]string))
+	assert.Equal(t, []int64{31, 42, 27}, Slice(accounts).Pluck("Age").Value().([]int64))
+	assert.Equal(t, []string{"Auckland", "", "Sydney"}, Slice(accounts).Pluck("Address.City").Value().([]string))
+	assert.Equal(t, 3, len(Slice(accounts).Pluck("CreatedAt").Value().([]time.Time)))
+
+	pointers := Slice(&accounts[0], &accounts[2], nil)
+	assert.Equal(t, []string{"NZ", "AU", ""}, pointers.Pluck("Address.Country").Value().([]string))
+
+	maps := Slice(map[string]interface{}{"a": 1}, map[string]interface{}{"a": "b"}, map[string]interface{}{})
+	assert.Equal(t, []interface{}{1, "b", nil}, maps.Pluck("a").Value().([]interface{}))
+
+	assert.PanicsWithValue(t, `tricks: slice.Pluck: no field "Nope" in tricks.testAccount`,
+		func() { Slice(accounts).Pluck("Nope") })
+	assert.PanicsWithValue(t, `tricks: slice.Pluck: field "secret" of tricks.testAccount is not exported`,
+		func() { Slice(accounts).Pluck("secret") })
+	assert.PanicsWithValue(t, `tricks: slice.Pluck: can't get field "Length" of string`,
+		func() { Slice(accounts).Pluck("Name.Length") })
+	assert.Panics(t, func() { Slice([]testAccount{}).Pluck("Nope") })
+}
+
+func TestSliceWhere(t *testing.T) {
+	accounts := testAccounts()
+
+	active := Slice(accounts).Where("Status", "active").Pluck("Name").Value().([]string)
+	assert.Equal(t, []string{"ann", "cat"}, active)
+
+	aged := Slice(accounts).Where("Age", 42).Pluck("Name").Value().([]string)
+	assert.Equal(t, []string{"bob"}, aged)
+
+	homeless := Slice(accounts).Where("Address", nil).Pluck("Name").Value().([]string)
+	assert.Equal(t, []string{"bob"}, homeless)
+
+	kiwis := Slice(accounts).Where("Address.Country", "NZ").Pluck("Name").Value().([]string)
+	assert.Equal(t, []string{"ann"}, kiwis)
+
+	assert.Equal(t, 0, Slice(accounts).Where("Status", "unknown").Len())
+	assert.Panics(t, func() { Slice(accounts).Where("status", "active") })
+}
+
+func TestSliceSortByField(t *testing.T) {
+	accounts := testAccounts()
+
+	Slice(accounts).SortByField("CreatedAt", false)
+	assert.Equal(t, []string{"bob", "cat", "ann"}, Slice(accounts).Pluck("Name").Value().([]string))
+
+	Slice(accounts).SortByField("Age", true)
+	assert.Equal(t, []string{"bob", "ann", "cat"}, Slice(accounts).Pluck("Name").Value().([]string))
+
+	// Structs can't be ordered.
+	assert.Panics(t, func() { Slice(accounts).SortByField("Address", false) })
+
+	// Stable: cat stays ahead of ann, where Age put it.
+	Slice(accounts).SortByField("Age", false)
+	Slice(accounts).SortByField("Status", false)
+	assert.Equal(t, []string{"cat", "ann", "bob"}, Slice(accounts).Pluck("Name").Value().([]string))
+
+	// Nils first: bob has no Address.
+	Slice(accounts).SortByField("Address.City", false)
+	assert.Equal(t, []string{"bob", "ann", "cat"}, Slice(accounts).Pluck("Name").Value().([]string))
+}
+
+func TestSliceGroupByField(t *testing.T) {
+	accounts := testAccounts()
+
+	grouped := Slice(accounts).GroupByField("Status").Value().(map[string][]testAccount)
+	assert.Equal(t, []string{"active", "closed"}, Map(grouped).Keys().Sort().Value().([]string))
+	assert.Equal(t, []string{"ann", "cat"}, Slice(grouped["active"]).Pluck("Name").Value().([]string))
+
+	byCountry := Slice(accounts).GroupByField("Address.Country").Value().(map[string][]testAccount)
+	assert.Equal(t, 3, len(byCountry))
+	assert.Equal(t, "bob", byCountry[""][0].Name)
+
+	assert.Panics(t, func() { Slice(accounts).GroupByField("Nope") })
+}
+
+func TestSliceOfArray(t *testing.T) {
+	array := [3]int{1, 2, 3}
+
+	copied := Slice(array)
+	assert.Equal(t, []int{1, 2, 3}, copied.Value().([]int))
+	copied.Reverse()
+	assert.Equal(t, [3]int{1, 2, 3}, array)
+
+	sliced := Slice(&array)
+	assert.Equal(t, []int{1, 2, 3}, sliced.Value().([]int))
+	sliced.Reverse()
+	assert.Equal(t, [3]int{3, 2, 1}, array)
+
+	assert.Equal(t, []int{}, Slice([0]int{}).Value().([]int))
+	assert.Equal(t, [][2]int{{1, 2}, {3, 4}}, Slice([2]int{1, 2}, [2]int{3, 4}).Value().([][2]int))
+
+	var nilArray *[3]int
+	assert.Equal(t, []*[3]int{nil}, Slice(nilArray).Value().([]*[3]int))
+}
+
+func TestSliceFlattenArrays(t *testing.T) {
+	five := []int{1, 2, 3, 4, 5}
+	assert.Equal(t, five, Slice([2][]int{{1, 2}, {3, 4, 5}}).Flatten().Value().([]int))
+	assert.Equal(t, five, Slice([][2]int{{1, 2}, {3, 4}}, 5).Flatten().Value().([]int))
+	assert.Equal(t, five, Slice([]interface{}{[1]int{1}, []int{2}, [3]interface{}{3, [1]int{4}, 5}}).Flatten().Value().([]int))
+}
@@ -0,0 +1,161 @@
+package tricks
+
+import (
+	"context"
+	"reflect"
+)
+
+// A TrickStream is a lazy sequence of values read from a channel. Map and
+// Filter return new streams straight away, and do their work in the
+// background, one value at a time, as values are asked for further down. The
+// stream ends when the channel is closed, or the context is done; either way,
+// each stage closes the channel it sends on when it stops.
+//
+// Each stage sends on an unbuffered channel, so a slow consumer holds up the
+// whole pipeline rather than letting values pile up.
+type TrickStream struct {
+	ctx   context.Context
+	ch    reflect.Value // <-chan T
+	clock Clock
+	err   *error // set by the stage sending on ch before it closes it
+}
+
+// Stream wraps a channel (`chan T` or `<-chan T`) in a TrickStream. The stream
+// stops early if ctx is done, in which case Collect and Emit return ctx.Err().
+func Stream(ctx context.Context, anyChan interface{}) *TrickStream {
+	v := reflect.ValueOf(anyChan)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("tricks: Stream: input is not a channel that can be received from")
+	}
+	return &TrickStream{ctx, v, realClock{}, nil}
+}
+
+// Chan returns the channel the stream's values come from, as a `<-chan T`.
+func (s *TrickStream) Chan() interface{} {
+	return s.ch.Convert(reflect.ChanOf(reflect.RecvDir, s.ch.Type().Elem())).Interface()
+}
+
+// recv returns the next value from the stream, and false once there are no
+// more. The error is ctx.Err() if the stream was cut short because the context
+// is done, and nil if the channel was closed at its end.
+func (s *TrickStream) recv() (reflect.Value, bool, error) {
+	chosen, val, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: s.ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctx.Done())},
+	})
+	switch {
+	case chosen == 1:
+		return val, false, s.ctx.Err()
+	case !ok:
+		return val, false, s.closedErr()
+	}
+	return val, true, nil
+}
+
+// closedErr returns why the channel was closed: nil if the stage sending on it
+// ran out of values, or ctx.Err() if it stopped because the context is done.
+func (s *TrickStream) closedErr() error {
+	if s.err == nil {
+		return nil
+	}
+	return *s.err
+}
+
+// send sends val on ch, and returns false if the context was done first.
+func (s *TrickStream) send(ch, val reflect.Value) bool {
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: ch, Send: val},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctx.Done())},
+	})
+	return chosen == 0
+}
+
+// pipe starts a new stage of the stream, sending values of type typ. Each value
+// received is passed to fn, along with a function to send values on.
+func (s *TrickStream) pipe(typ reflect.Type, fn func(val reflect.Value, send func(reflect.Value) bool) bool) *TrickStream {
+	out := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, typ), 0)
+	var err error
+	send := func(val reflect.Value) bool {
+		if !s.send(out, val) {
+			err = s.ctx.Err()
+			return false
+		}
+		return true
+	}
+	go func() {
+		defer out.Close()
+		for {
+			val, ok, recvErr := s.recv()
+			if !ok {
+				err = recvErr
+				return
+			}
+			if !fn(val, send) {
+				return
+			}
+		}
+	}()
+	return &TrickStream{s.ctx, out, s.clock, &err}
+}
+
+// Map returns a new stream of the results of applying the given `func(T) X` to
+// each value of this one.
+func (s *TrickStream) Map(fn interface{}) *TrickStream {
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidMapFunc(f.Type(), s.ch.Type()) {
+		panic("tricks: stream.Map: invalid function type")
+	}
+	return s.pipe(f.Type().Out(0), func(val reflect.Value, send func(reflect.Value) bool) bool {
+		return send(f.Call([]reflect.Value{val})[0])
+	})
+}
+
+// Filter returns a new stream of only the values of this one for which the
+// given `func(T) bool` returns true.
+func (s *TrickStream) Filter(fn interface{}) *TrickStream {
+	f := reflect.ValueOf(fn)
+	if !f.IsValid() || !isValidBoolFunc(f.Type(), s.ch.Type()) {
+		panic("tricks: stream.Filter: invalid function type")
+	}
+	return s.pipe(s.ch.Type().Elem(), func(val reflect.Value, send func(reflect.Value) bool) bool {
+		if f.Call([]reflect.Value{val})[0].Bool() {
+			return send(val)
+		}
+		return true
+	})
+}
+
+// Collect reads the rest of the stream into a slice (`[]T`), waiting until the
+// channel is closed. If the stream is cut short because the context is done, it
+// returns what it has read so far, along with ctx.Err().
+func (s *TrickStream) Collect() (TrickSlice, error) {
+	out := reflect.MakeSlice(reflect.SliceOf(s.ch.Type().Elem()), 0, 0)
+	for {
+		val, ok, err := s.recv()
+		if !ok {
+			return TrickSlice(out), err
+		}
+		out = reflect.Append(out, val)
+	}
+}
+
+// Emit sends the rest of the stream on the channel out (a `chan T` or
+// `chan<- T`), waiting for each value to be taken before reading the next. It
+// returns once the stream's channel is closed, or with ctx.Err() if the context
+// is done first. Emit doesn't close out.
+func (s *TrickStream) Emit(out interface{}) error {
+	o := reflect.ValueOf(out)
+	if o.Kind() != reflect.Chan || o.Type().ChanDir()&reflect.SendDir == 0 ||
+		!s.ch.Type().Elem().AssignableTo(o.Type().Elem()) {
+		panic("tricks: stream.Emit: output is not a channel of the stream's type")
+	}
+	for {
+		val, ok, err := s.recv()
+		if !ok {
+			return err
+		}
+		if !s.send(o, val) {
+			return s.ctx.Err()
+		}
+	}
+}
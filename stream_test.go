@@ -0,0 +1,118 @@
+package tricks
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countTo(n int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= n; i++ {
+			ch <- i
+		}
+	}()
+	return ch
+}
+
+func TestStreamCollect(t *testing.T) {
+	out, err := Stream(context.Background(), countTo(10)).
+		Filter(func(n int) bool { return n%2 == 0 }).
+		Map(func(n int) string { return strconv.Itoa(n * n) }).
+		Collect()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"4", "16", "36", "64", "100"}, out.Value())
+
+	empty, err := Stream(context.Background(), countTo(0)).Collect()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{}, empty.Value())
+}
+
+func TestStreamEmit(t *testing.T) {
+	out := make(chan int)
+	done := make(chan error)
+	go func() {
+		done <- Stream(context.Background(), countTo(3)).Map(func(n int) int { return -n }).Emit(out)
+	}()
+
+	assert.Equal(t, -1, <-out)
+	assert.Equal(t, -2, <-out)
+	assert.Equal(t, -3, <-out)
+	assert.NoError(t, <-done)
+}
+
+func TestStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	forever := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case forever <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := make(chan int)
+	done := make(chan error)
+	go func() {
+		done <- Stream(ctx, forever).Filter(func(n int) bool { return n > 2 }).Emit(out)
+	}()
+
+	assert.Equal(t, 3, <-out)
+	assert.Equal(t, 4, <-out)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	vals, err := Stream(ctx, make(chan int)).Collect()
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, vals.Len())
+}
+
+// lateContext is done by the time Err is called, but never says so on Done,
+// as though it were cancelled just as the stream ended.
+type lateContext struct{ context.Context }
+
+func (lateContext) Err() error { return context.Canceled }
+
+func TestStreamCancelAfterEnd(t *testing.T) {
+	// A stream that ran to its end is complete, even if the context is done
+	// by the time it returns.
+	ctx := lateContext{context.Background()}
+	out, err := Stream(ctx, countTo(3)).Map(func(n int) int { return -n }).Collect()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{-1, -2, -3}, out.Value())
+
+	out, err = Stream(ctx, countTo(5)).Batch(2, 0).Collect()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, out.Value())
+
+	emitted := make(chan int, 3)
+	assert.NoError(t, Stream(ctx, countTo(3)).Emit(emitted))
+	assert.Equal(t, 3, len(emitted))
+}
+
+func TestStreamChan(t *testing.T) {
+	s := Stream(context.Background(), countTo(2)).Map(func(n int) bool { return n > 1 })
+	ch := s.Chan().(<-chan bool)
+	assert.False(t, <-ch)
+	assert.True(t, <-ch)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestStreamPanics(t *testing.T) {
+	s := Stream(context.Background(), make(chan int))
+	assert.Panics(t, func() { Stream(context.Background(), []int{1}) })
+	assert.Panics(t, func() { Stream(context.Background(), make(chan<- int)) })
+	assert.Panics(t, func() { s.Map(func(s string) string { return s }) })
+	assert.Panics(t, func() { s.Filter(func(n int) int { return n }) })
+	assert.Panics(t, func() { s.Emit(make(chan string)) })
+	assert.Panics(t, func() { s.Emit(make(<-chan int)) })
+}
@@ -0,0 +1,287 @@
+package tricks
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type TrickStruct reflect.Value
+
+// Struct wraps a struct, or a pointer to a struct. Only a struct passed by
+// pointer can have its fields changed with Set.
+func Struct(anyStruct interface{}) TrickStruct {
+	v := reflect.ValueOf(anyStruct)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic("tricks: Struct: input is not a struct")
+	}
+	return TrickStruct(v)
+}
+
+func (ts TrickStruct) Value() interface{} {
+	return reflect.Value(ts).Interface()
+}
+
+// Get returns the value of the named field. Fields may be nested, like
+// "Address.City", passing through pointers and embedded structs; if there's a
+// nil pointer along the way, Get returns the field type's zero value. Get
+// panics if the field doesn't exist or isn't exported.
+func (ts TrickStruct) Get(field string) interface{} {
+	v := reflect.Value(ts)
+	path := splitFieldPath(field)
+	typ, err := fieldType(v.Type(), path)
+	if err != nil {
+		panic("tricks: struct.Get: " + err.Error())
+	}
+	f, err := fieldValue(v, path)
+	if err != nil {
+		panic("tricks: struct.Get: " + err.Error())
+	}
+	if !f.IsValid() {
+		return reflect.Zero(typ).Interface()
+	}
+	return f.Interface()
+}
+
+// Set sets the value of the named field, converting value to the field's type
+// the same way Decode does. Fields are named as for Get, and any nil pointers
+// along the way are filled in with new zero values. Set panics if the struct
+// wasn't passed by pointer, the field doesn't exist or isn't exported, or value
+// can't be converted.
+func (ts TrickStruct) Set(field string, value interface{}) {
+	v := reflect.Value(ts)
+	if !v.CanSet() {
+		panic("tricks: struct.Set: struct is not addressable (pass a pointer to Struct)")
+	}
+	f, err := settableField(v, splitFieldPath(field))
+	if err != nil {
+		panic("tricks: struct.Set: " + err.Error())
+	}
+	if err := convertInto(f, reflect.ValueOf(value), field+"."); err != nil {
+		de, ok := err.(*DecodeError)
+		if !ok {
+			de = &DecodeError{field, err}
+		}
+		panic("tricks: struct.Set: field " + de.Field + ": " + de.Err.Error())
+	}
+}
+
+// settableField is like fieldValue, but allocates nil pointers as it goes so
+// the field it returns can always be set. It only passes through structs.
+func settableField(v reflect.Value, path []string) (reflect.Value, error) {
+	var err error
+	for _, name := range path {
+		if v, err = allocIndirect(v); err != nil {
+			return reflect.Value{}, err
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("can't set field %q of %s", name, v.Type())
+		}
+		f := lookupField(v.Type(), name)
+		if f.err != nil {
+			return reflect.Value{}, f.err
+		}
+		if v, err = allocFieldByIndex(v, f.index); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// allocIndirect follows pointers, allocating new values for any nil ones.
+func allocIndirect(v reflect.Value) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return reflect.Value{}, fmt.Errorf("can't set nil embedded pointer to unexported %s", v.Type().Elem())
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v, nil
+}
+
+// allocFieldByIndex is like v.FieldByIndex, but allocates any nil embedded
+// pointers along the way.
+func allocFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	var err error
+	for i, x := range index {
+		if i > 0 {
+			if v, err = allocIndirect(v); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// ToMap returns a map[string]interface{} of the struct's exported fields,
+// following the same rules as encoding/json: fields are named by their `json`
+// tag if they have one, fields tagged "-" are skipped, "omitempty" skips zero
+// values, and the fields of embedded structs are promoted. Nested structs are
+// converted to maps too, unless they know how to marshal themselves (like
+// time.Time); all other values are kept as they are.
+func (ts TrickStruct) ToMap() TrickMap {
+	return TrickMap(structToMap(reflect.Value(ts), "json"))
+}
+
+func structToMap(v reflect.Value, tagKey string) reflect.Value {
+	out := reflect.ValueOf(make(map[string]interface{}))
+	for _, f := range structFields(v.Type(), tagKey) {
+		val := fieldByIndex(v, f.index)
+		if !val.IsValid() || f.omitEmpty && isEmptyValue(val) {
+			continue
+		}
+		if s := indirect(val); s.Kind() == reflect.Struct && !marshalsItself(s.Type()) {
+			val = structToMap(s, tagKey)
+		}
+		out.SetMapIndex(reflect.ValueOf(f.name), val)
+	}
+	return out
+}
+
+var (
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func marshalsItself(typ reflect.Type) bool {
+	ptr := reflect.PtrTo(typ)
+	return typ.Implements(typeJSONMarshaler) || ptr.Implements(typeJSONMarshaler) ||
+		typ.Implements(typeTextMarshaler) || ptr.Implements(typeTextMarshaler)
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// A taggedField is an exported struct field, named by its struct tag.
+type taggedField struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+type taggedFieldsKey struct {
+	typ    reflect.Type
+	tagKey string
+}
+
+var taggedFieldsCache = struct {
+	sync.RWMutex
+	m map[taggedFieldsKey][]taggedField
+}{m: make(map[taggedFieldsKey][]taggedField)}
+
+// structFields lists the exported fields of a struct type, named by the given
+// tag key (like "json" or "csv") the way encoding/json does it. The fields of
+// embedded structs without a tag name are promoted, with shallower fields
+// hiding deeper ones of the same name.
+func structFields(typ reflect.Type, tagKey string) []taggedField {
+	key := taggedFieldsKey{typ, tagKey}
+	taggedFieldsCache.RLock()
+	fields, ok := taggedFieldsCache.m[key]
+	taggedFieldsCache.RUnlock()
+	if ok {
+		return fields
+	}
+
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	seen := make(map[string]bool) // names found at shallower depths
+	level := []embedded{{typ, nil}}
+	visited := make(map[reflect.Type]bool)
+	for len(level) > 0 {
+		var next []embedded
+		names := make(map[string]bool) // names found at this depth
+		for _, e := range level {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				index := append(append([]int(nil), e.index...), i)
+				name, opts := parseTag(sf.Tag.Get(tagKey))
+				if name == "-" && opts == "" {
+					continue
+				}
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{ft, index})
+					continue
+				}
+				if sf.PkgPath != "" { // unexported
+					continue
+				}
+				if name == "" {
+					name = sf.Name
+				}
+				if seen[name] || names[name] {
+					continue
+				}
+				names[name] = true
+				fields = append(fields, taggedField{
+					name:      name,
+					index:     index,
+					typ:       sf.Type,
+					omitEmpty: hasTagOption(opts, "omitempty"),
+				})
+			}
+		}
+		for name := range names {
+			seen[name] = true
+		}
+		level = next
+	}
+
+	taggedFieldsCache.Lock()
+	taggedFieldsCache.m[key] = fields
+	taggedFieldsCache.Unlock()
+	return fields
+}
+
+// parseTag splits a struct tag value like "name,omitempty" into the name and
+// its comma-separated options.
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package tricks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPet struct {
+	Name string `json:"name"`
+}
+
+type testMeta struct {
+	Created time.Time `json:"created"`
+	Version int       `json:"version,omitempty"`
+}
+
+type testOwner struct {
+	*testMeta
+	ID       int               `json:"id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Password string            `json:"-"`
+	Pet      *testPet          `json:"pet"`
+	Tags     []string          `json:"tags"`
+	Scores   map[string]uint8  `json:"scores,omitempty"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Admin    bool
+	private  int
+}
+
+func TestStructGet(t *testing.T) {
+	owner := testOwner{ID: 7, Name: "ann", Pet: &testPet{"rex"}}
+
+	assert.Equal(t, 7, Struct(owner).Get("ID"))
+	assert.Equal(t, "rex", Struct(&owner).Get("Pet.Name"))
+	assert.Equal(t, time.Time{}, Struct(owner).Get("Created")) // through a nil embedded pointer
+
+	owner.Pet = nil
+	assert.Equal(t, "", Struct(owner).Get("Pet.Name"))
+
+	assert.Panics(t, func() { Struct(owner).Get("Nope") })
+	assert.Panics(t, func() { Struct(owner).Get("private") })
+	assert.Panics(t, func() { Struct(42) })
+	assert.Panics(t, func() { Struct(nil) })
+}
+
+func TestStructSet(t *testing.T) {
+	owner := testOwner{testMeta: &testMeta{}}
+
+	Struct(&owner).Set("Name", "bob")
+	Struct(&owner).Set("ID", 42.0)
+	Struct(&owner).Set("Pet.Name", "rex")
+	Struct(&owner).Set("Version", "3")
+	Struct(&owner).Set("Tags", []interface{}{"a", "b"})
+
+	assert.Equal(t, "bob", owner.Name)
+	assert.Equal(t, 42, owner.ID)
+	assert.Equal(t, &testPet{"rex"}, owner.Pet)
+	assert.Equal(t, 3, owner.Version)
+	assert.Equal(t, []string{"a", "b"}, owner.Tags)
+
+	assert.PanicsWithValue(t, "tricks: struct.Set: struct is not addressable (pass a pointer to Struct)",
+		func() { Struct(owner).Set("Name", "ann") })
+	assert.PanicsWithValue(t, `tricks: struct.Set: field ID: can't convert 4.5 to int`,
+		func() { Struct(&owner).Set("ID", 4.5) })
+	assert.PanicsWithValue(t, `tricks: struct.Set: field Tags.1: can't convert 1 to string`,
+		func() { Struct(&owner).Set("Tags", []interface{}{"a", 1}) })
+	assert.PanicsWithValue(t, `tricks: struct.Set: no field "Nope" in tricks.testOwner`,
+		func() { Struct(&owner).Set("Nope", 1) })
+
+	owner.testMeta = nil
+	assert.PanicsWithValue(t, `tricks: struct.Set: can't set nil embedded pointer to unexported tricks.testMeta`,
+		func() { Struct(&owner).Set("Version", 1) })
+}
+
+func TestStructToMap(t *testing.T) {
+	created := time.Date(2016, 10, 5, 9, 5, 9, 0, time.UTC)
+	owner := testOwner{
+		testMeta: &testMeta{Created: created},
+		ID:       7,
+		Name:     "ann",
+		Password: "hunter2",
+		Pet:      &testPet{"rex"},
+		Tags:     []string{"x"},
+		Admin:    true,
+	}
+
+	expected := map[string]interface{}{
+		"created": created,
+		"id":      7,
+		"name":    "ann",
+		"pet":     map[string]interface{}{"name": "rex"},
+		"tags":    []string{"x"},
+		"Admin":   true,
+	}
+	assert.Equal(t, expected, Struct(owner).ToMap().Value())
+
+	owner.testMeta = nil
+	owner.Pet = nil
+	m := Struct(&owner).ToMap()
+	assert.False(t, m.HasKeys("created"))
+	assert.Equal(t, (*testPet)(nil), m.Value().(map[string]interface{})["pet"])
+}
+
+func TestDecode(t *testing.T) {
+	src := map[string]interface{}{
+		"id":      float64(7),
+		"NAME":    "ann",
+		"pet":     map[string]interface{}{"name": "rex"},
+		"tags":    []interface{}{"a", "b"},
+		"scores":  map[string]interface{}{"maths": 90.0},
+		"created": "2016-10-05T09:05:09Z",
+		"version": 2,
+		"Admin":   "true",
+		"unknown": "ignored",
+	}
+
+	owner := testOwner{testMeta: &testMeta{}}
+	assert.NoError(t, Decode(src, &owner))
+	assert.Equal(t, 7, owner.ID)
+	assert.Equal(t, "ann", owner.Name)
+	assert.Equal(t, &testPet{"rex"}, owner.Pet)
+	assert.Equal(t, []string{"a", "b"}, owner.Tags)
+	assert.Equal(t, map[string]uint8{"maths": 90}, owner.Scores)
+	assert.Equal(t, time.Date(2016, 10, 5, 9, 5, 9, 0, time.UTC), owner.Created)
+	assert.Equal(t, 2, owner.Version)
+	assert.True(t, owner.Admin)
+
+	// Round trip.
+	again := testOwner{testMeta: &testMeta{}}
+	assert.NoError(t, Decode(Struct(owner).ToMap(), &again))
+	assert.Equal(t, owner, again)
+
+	err := Decode(map[string]interface{}{"scores": map[string]interface{}{"art": 300}}, &owner)
+	assert.EqualError(t, err, "tricks: Decode: field scores.art: can't convert 300 to uint8")
+
+	err = Decode(map[string]interface{}{"pet": map[string]interface{}{"name": 1}}, &owner)
+	assert.Equal(t, "pet.name", err.(*DecodeError).Field)
+
+	err = Decode(map[string]interface{}{"id": "seven"}, &owner)
+	assert.EqualError(t, err, `tricks: Decode: field id: can't convert "seven" to int`)
+
+	assert.Panics(t, func() { Decode(map[int]int{}, &owner) })
+	assert.Panics(t, func() { Decode(src, owner) })
+}
@@ -0,0 +1,221 @@
+package tricks
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableOptions control how Table renders a slice or map. A nil *TableOptions
+// is the same as the zero value: a plain text table, with all the columns, and
+// no limit on their width.
+type TableOptions struct {
+	// Columns chooses which columns to show, and in what order, by their
+	// header names. If nil, all the columns are shown. Tables of maps always
+	// have just the two columns, Key and Value.
+	Columns []string
+
+	// MaxWidth cuts any cell longer than this many characters short, ending
+	// it with "…". Zero means no limit.
+	MaxWidth int
+
+	// Markdown renders the table in Markdown, with the columns separated by
+	// pipes.
+	Markdown bool
+
+	// Formatters are used when showing values of the given types, the same
+	// way as for CSVOptions.
+	Formatters map[reflect.Type]func(interface{}) string
+}
+
+// Table writes the slice to w as a table, one row per element, with the
+// columns lined up. The elements may be structs (or pointers to structs), in
+// which case the columns are their exported fields, named by their `table`
+// tags if they have them; maps with string keys, in which case the columns are
+// all the keys found, sorted; or anything else, in which case there's a single
+// column, Value. Columns of numbers are aligned to the right.
+func (ts TrickSlice) Table(w io.Writer, opts *TableOptions) error {
+	if opts == nil {
+		opts = &TableOptions{}
+	}
+	v := reflect.Value(ts)
+
+	t := newTable(opts)
+	if !holdsRecords(v) {
+		t.addHeader("Value")
+		for i := 0; i < v.Len(); i++ {
+			t.addRow(v.Index(i))
+		}
+		return t.write(w)
+	}
+
+	cols := columnsOf("slice.Table", v, "table", opts.Columns)
+	for _, col := range cols {
+		t.addHeader(col.name)
+	}
+	cells := make([]reflect.Value, len(cols))
+	for i := 0; i < v.Len(); i++ {
+		row := indirect(v.Index(i))
+		for j, col := range cols {
+			cells[j] = col.cell(row)
+		}
+		t.addRow(cells...)
+	}
+	return t.write(w)
+}
+
+// holdsRecords returns true if the elements of v are structs or maps, or are
+// interfaces that all hold maps (or nil), else false.
+func holdsRecords(v reflect.Value) bool {
+	elemType := v.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	switch elemType.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Interface:
+		found := false
+		for i := 0; i < v.Len(); i++ {
+			row := indirect(v.Index(i))
+			if row.IsValid() && row.Kind() != reflect.Map {
+				return false
+			}
+			found = found || row.IsValid()
+		}
+		return found
+	}
+	return false
+}
+
+// Table writes the map to w as a table with two columns, Key and Value, one
+// row per entry, sorted as for Entries.
+func (tm TrickMap) Table(w io.Writer, opts *TableOptions) error {
+	if opts == nil {
+		opts = &TableOptions{}
+	}
+	v := reflect.Value(tm)
+
+	t := newTable(opts)
+	t.addHeader("Key")
+	t.addHeader("Value")
+	for _, key := range sortedKeys(v) {
+		t.addRow(key, v.MapIndex(key))
+	}
+	return t.write(w)
+}
+
+// A table collects formatted cells, and works out how to line them up.
+type table struct {
+	opts    *TableOptions
+	header  []string
+	rows    [][]string
+	numeric []bool // whether each column holds only numbers (and blanks)
+	blank   []bool // whether each column is all blanks
+}
+
+func newTable(opts *TableOptions) *table {
+	return &table{opts: opts}
+}
+
+func (t *table) addHeader(name string) {
+	t.header = append(t.header, t.clean(name))
+	t.numeric = append(t.numeric, true)
+	t.blank = append(t.blank, true)
+}
+
+func (t *table) addRow(cells ...reflect.Value) {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = t.clean(formatCell(t.opts.Formatters, cell))
+		if row[i] != "" {
+			t.blank[i] = false
+			if !isNumber(indirect(cell)) {
+				t.numeric[i] = false
+			}
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// clean puts a cell on one line, shortens it to MaxWidth, and escapes it for
+// Markdown if need be.
+func (t *table) clean(s string) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	if max := t.opts.MaxWidth; max > 0 && utf8.RuneCountInString(s) > max {
+		s = string([]rune(s)[:max-1]) + "…"
+	}
+	if t.opts.Markdown {
+		s = strings.Replace(s, "|", `\|`, -1)
+	}
+	return s
+}
+
+func (t *table) write(w io.Writer) error {
+	if len(t.header) == 0 {
+		return nil
+	}
+	for i := range t.numeric {
+		t.numeric[i] = t.numeric[i] && !t.blank[i]
+	}
+
+	widths := make([]int, len(t.header))
+	for i, name := range t.header {
+		widths[i] = utf8.RuneCountInString(name)
+		if t.opts.Markdown && widths[i] < 3 {
+			widths[i] = 3 // room for "---"
+		}
+		for _, row := range t.rows {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if t.opts.Markdown {
+				if i == 0 {
+					buf.WriteString("|")
+				}
+				buf.WriteString(" ")
+			} else if i > 0 {
+				buf.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if t.numeric[i] {
+				buf.WriteString(pad + cell)
+			} else {
+				buf.WriteString(cell + pad)
+			}
+			if t.opts.Markdown {
+				buf.WriteString(" |")
+			}
+		}
+		if !t.opts.Markdown {
+			// Blank cells at the end of a row would leave trailing spaces.
+			line := bytes.TrimRight(buf.Bytes(), " ")
+			buf.Truncate(len(line))
+		}
+		buf.WriteString("\n")
+	}
+
+	writeRow(t.header)
+	rule := make([]string, len(t.header))
+	for i, width := range widths {
+		rule[i] = strings.Repeat("-", width)
+		if t.opts.Markdown && t.numeric[i] {
+			rule[i] = rule[i][:width-1] + ":"
+		}
+	}
+	writeRow(rule)
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,83 @@
+package tricks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTableRow struct {
+	Name  string  `table:"NAME"`
+	Score float64 `table:"SCORE"`
+	Note  string  `table:"NOTE,omitempty"`
+	Skip  bool    `table:"-"`
+}
+
+func renderTable(ts TrickSlice, opts *TableOptions) string {
+	var buf bytes.Buffer
+	if err := ts.Table(&buf, opts); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func TestSliceTable(t *testing.T) {
+	rows := []testTableRow{
+		{"ann", 9.5, "top of the class", false},
+		{"bob", 10, "", true},
+		{"catherine", 7.25, "line one\nline two", false},
+	}
+
+	assert.Equal(t, ""+
+		"NAME       SCORE  NOTE\n"+
+		"---------  -----  -----------------\n"+
+		"ann          9.5  top of the class\n"+
+		"bob           10\n"+
+		"catherine   7.25  line one line two\n",
+		renderTable(Slice(rows), nil))
+
+	assert.Equal(t, ""+
+		"NOTE    NAME\n"+
+		"------  ------\n"+
+		"top o…  ann\n"+
+		"        bob\n"+
+		"line …  cathe…\n",
+		renderTable(Slice(rows), &TableOptions{Columns: []string{"NOTE", "NAME"}, MaxWidth: 6}))
+
+	assert.Panics(t, func() { renderTable(Slice(rows), &TableOptions{Columns: []string{"Name"}}) })
+}
+
+func TestSliceTableMarkdown(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"id": 1, "cmd": "a | b"},
+		map[string]interface{}{"id": 22},
+	}
+
+	assert.Equal(t, ""+
+		"| cmd    |  id |\n"+
+		"| ------ | --: |\n"+
+		"| a \\| b |   1 |\n"+
+		"|        |  22 |\n",
+		renderTable(Slice(rows), &TableOptions{Markdown: true}))
+}
+
+func TestSliceTableOfValues(t *testing.T) {
+	assert.Equal(t, "Value\n-----\n    1\n   20\n", renderTable(Slice([]int{1, 20}), nil))
+	assert.Equal(t, "Value\n-----\na\n1\n", renderTable(Slice([]interface{}{"a", 1}), nil))
+	assert.Equal(t, "", renderTable(Slice([]map[string]int{}), nil))
+}
+
+func TestMapTable(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Map(map[string]int{"beta": 2, "alpha": 10}).Table(&buf, nil))
+	assert.Equal(t, ""+
+		"Key    Value\n"+
+		"-----  -----\n"+
+		"alpha     10\n"+
+		"beta       2\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Map(map[int]string{1: "x"}).Table(&buf, &TableOptions{Markdown: true}))
+	assert.Equal(t, "| Key | Value |\n| --: | ----- |\n|   1 | x     |\n", buf.String())
+}